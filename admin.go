@@ -0,0 +1,337 @@
+package ligno
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultTailDuration is duration of tailing logger output if not
+	// provided in request.
+	defaultTailDuration = 30 * time.Second
+	// maxTailDuration is max allowed duration of tailing logger output.
+	maxTailDuration = 10 * time.Minute
+	// tailBufferSize is max number of records waiting to be sent to single
+	// tailing client. Records are dropped when client can not keep up.
+	tailBufferSize = 1000
+)
+
+// LoggerInfo holds information about state of single logger.
+type LoggerInfo struct {
+	Name               string `json:"name"`
+	Level              Level  `json:"level"`
+	EffectiveLevel     Level  `json:"effective_level"`
+	Handler            string `json:"handler"`
+	Queued             int    `json:"queued"`
+	PreventPropagation bool   `json:"prevent_propagation"`
+}
+
+// loggerInfo returns information about current state of logger.
+func (l *Logger) loggerInfo() LoggerInfo {
+	return LoggerInfo{
+		Name:               l.FullName(),
+		Level:              l.Level(),
+		EffectiveLevel:     l.EffectiveLevel(),
+		Handler:            fmt.Sprintf("%T", l.Handler()),
+		Queued:             int(atomic.LoadInt32(&l.toProcess)),
+		PreventPropagation: l.PreventPropagation(),
+	}
+}
+
+// AdminHandler returns http.Handler for inspecting and reconfiguring logger
+// tree at runtime. It supports following requests:
+//
+//	GET                               lists all loggers as JSON
+//	GET ?tail=name&duration=30s       streams records of logger as plain text
+//	HEAD                              same as GET, without response body
+//	POST logger=name&level=DEBUG      changes level of logger (add
+//	                                  recursive=true to change whole subtree)
+//	POST logger=name&propagate=false  changes propagation of records to parent
+//
+// Empty logger name denotes root logger. Since this handler allows
+// changing logging behavior, make sure it is not publicly accessible.
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if r.Method == http.MethodHead {
+				w = headResponseWriter{w}
+			}
+			if _, ok := r.URL.Query()["tail"]; ok {
+				adminTail(w, r)
+				return
+			}
+			adminList(w)
+		case http.MethodPost, http.MethodPut:
+			adminUpdate(w, r)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// adminList writes information about all loggers.
+func adminList(w http.ResponseWriter) {
+	infos := make([]LoggerInfo, 0)
+	rootLogger.walk(func(l *Logger) {
+		infos = append(infos, l.loggerInfo())
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	writeJSON(w, map[string]interface{}{"loggers": infos})
+}
+
+// adminUpdate changes level or propagation of logger.
+func adminUpdate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	l, ok := findLogger(r.Form.Get("logger"))
+	if !ok {
+		http.Error(w, "logger not found", http.StatusNotFound)
+		return
+	}
+	// validate everything before applying any change
+	var level Level
+	var recursive, propagate bool
+	var err error
+	levelStr, setLevel := r.Form["level"]
+	if setLevel {
+		if level, err = ParseLevel(levelStr[0]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if recursive, err = parseBoolParam(r.Form.Get("recursive")); err != nil {
+			http.Error(w, "recursive: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	propagateStr, setPropagate := r.Form["propagate"]
+	if setPropagate {
+		if propagate, err = strconv.ParseBool(propagateStr[0]); err != nil {
+			http.Error(w, "propagate: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if setLevel {
+		if recursive {
+			l.SetLevelRecursive(level)
+		} else {
+			l.SetLevel(level)
+		}
+	}
+	if setPropagate {
+		l.SetPreventPropagation(!propagate)
+	}
+	writeJSON(w, l.loggerInfo())
+}
+
+// adminTail attaches tail sink to logger and streams records it receives
+// to client until requested duration expires or client goes away.
+func adminTail(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	l, ok := findLogger(query.Get("tail"))
+	if !ok {
+		http.Error(w, "logger not found", http.StatusNotFound)
+		return
+	}
+	duration := defaultTailDuration
+	if durationStr := query.Get("duration"); durationStr != "" {
+		var err error
+		if duration, err = time.ParseDuration(durationStr); err != nil || duration <= 0 {
+			http.Error(w, "invalid duration", http.StatusBadRequest)
+			return
+		}
+	}
+	if duration > maxTailDuration {
+		duration = maxTailDuration
+	}
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sink := make(chan []byte, tailBufferSize)
+	addTailSink(l, sink)
+	defer removeTailSink(l, sink)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+	// send writes provided message and all other waiting messages
+	send := func(message []byte) error {
+		for {
+			if _, err := w.Write(message); err != nil {
+				return err
+			}
+			select {
+			case message = <-sink:
+				continue
+			default:
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		}
+	}
+	for {
+		select {
+		case message := <-sink:
+			if send(message) != nil {
+				return
+			}
+		case <-deadline.C:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// tails holds tail handlers currently attached to loggers.
+var tails = struct {
+	sync.Mutex
+	handlers map[*Logger]*tailHandler
+}{
+	handlers: make(map[*Logger]*tailHandler),
+}
+
+// addTailSink attaches sink to logger. All sinks of logger share single
+// tail handler, which wraps original handler of logger.
+func addTailSink(l *Logger, sink chan []byte) {
+	tails.Lock()
+	defer tails.Unlock()
+	th, ok := tails.handlers[l]
+	// handler might have been replaced while tailing, in which case tail
+	// handler wraps new one
+	if !ok || l.Handler() != th {
+		th = &tailHandler{
+			handler:   l.Handler(),
+			formatter: ThemedTerminalFormat(NoColorTheme),
+			sinks:     make(map[chan []byte]struct{}),
+		}
+		tails.handlers[l] = th
+		l.SetHandler(th)
+	}
+	th.add(sink)
+}
+
+// removeTailSink detaches sink from logger. Once last sink is removed,
+// original handler is restored, unless handler was replaced meanwhile.
+func removeTailSink(l *Logger, sink chan []byte) {
+	tails.Lock()
+	defer tails.Unlock()
+	th, ok := tails.handlers[l]
+	if !ok {
+		return
+	}
+	if th.remove(sink) > 0 {
+		return
+	}
+	delete(tails.handlers, l)
+	if l.Handler() == th {
+		l.SetHandler(th.handler)
+	}
+}
+
+// tailHandler passes records to original handler of logger and sends them
+// formatted to all attached sinks.
+type tailHandler struct {
+	handler   Handler
+	formatter Formatter
+	mu        sync.RWMutex
+	sinks     map[chan []byte]struct{}
+}
+
+// add attaches sink to tail handler.
+func (th *tailHandler) add(sink chan []byte) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.sinks[sink] = struct{}{}
+}
+
+// remove detaches sink from tail handler and returns number of remaining
+// sinks.
+func (th *tailHandler) remove(sink chan []byte) int {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	delete(th.sinks, sink)
+	return len(th.sinks)
+}
+
+// Handle passes record to original handler and sends it to all sinks,
+// dropping it for sinks that are full.
+func (th *tailHandler) Handle(record Record) error {
+	var err error
+	if th.handler != nil {
+		err = th.handler.Handle(record)
+	}
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+	if len(th.sinks) == 0 {
+		return err
+	}
+	message := th.formatter.Format(record)
+	for sink := range th.sinks {
+		select {
+		case sink <- message:
+		default:
+		}
+	}
+	return err
+}
+
+// Close closes original handler if it implements HandlerCloser interface.
+func (th *tailHandler) Close() {
+	if handlerCloser, ok := th.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// Flush flushes original handler if it implements HandlerFlusher interface.
+func (th *tailHandler) Flush() error {
+	return flushHandler(th.handler)
+}
+
+// parseBoolParam parses boolean request parameter, empty value being false.
+func parseBoolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// headResponseWriter discards response body, for responding to HEAD requests.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Write discards provided data.
+func (w headResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// writeJSON writes provided value as JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	enc.Encode(v)
+}
@@ -0,0 +1,187 @@
+package ligno
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminHandlerList(t *testing.T) {
+	name := "admin." + randString()
+	GetLoggerOptions(name, LoggerOptions{Level: WARNING, PreventPropagation: true})
+	server := httptest.NewServer(AdminHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Loggers []LoggerInfo `json:"loggers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, info := range body.Loggers {
+		if info.Name == name {
+			found = true
+			if info.Level != WARNING || !info.PreventPropagation {
+				t.Errorf("Unexpected logger info: %+v", info)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Logger %s not found in list.", name)
+	}
+}
+
+func TestAdminHandlerUpdate(t *testing.T) {
+	name := "admin." + randString()
+	l := GetLoggerOptions(name, LoggerOptions{Level: WARNING})
+	child := GetLogger(name + ".child")
+	server := httptest.NewServer(AdminHandler())
+	defer server.Close()
+
+	resp, err := http.PostForm(server.URL, url.Values{
+		"logger":    {name},
+		"level":     {"debug"},
+		"recursive": {"true"},
+		"propagate": {"false"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", resp.StatusCode)
+	}
+	if l.Level() != DEBUG || child.Level() != DEBUG || !l.PreventPropagation() {
+		t.Errorf("Expected logger to be reconfigured, got level %s, child level %s", l.Level(), child.Level())
+	}
+
+	resp, err = http.PostForm(server.URL, url.Values{"logger": {name}, "level": {"bogus"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected bad request for unknown level, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandlerTail(t *testing.T) {
+	name := "admin." + randString()
+	original := NullHandler()
+	l := GetLoggerOptions(name, LoggerOptions{Handler: original, PreventPropagation: true})
+	server := httptest.NewServer(AdminHandler())
+	defer server.Close()
+
+	go func() {
+		// wait for tailing handler to be attached
+		for {
+			if _, ok := l.Handler().(*tailHandler); ok {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		l.Info("tailed message")
+	}()
+	resp, err := http.Get(server.URL + "?tail=" + name + "&duration=300ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "tailed message") {
+		t.Errorf("Expected tailed message in response, got %q", content)
+	}
+	if _, ok := l.Handler().(HandlerFunc); !ok {
+		t.Error("Expected original handler to be restored.")
+	}
+}
+
+func TestAdminHandlerOverlappingTails(t *testing.T) {
+	name := "admin." + randString()
+	original := NullHandler()
+	l := GetLoggerOptions(name, LoggerOptions{Handler: original, PreventPropagation: true})
+	server := httptest.NewServer(AdminHandler())
+	defer server.Close()
+
+	tail := func(duration string, content chan<- string) {
+		resp, err := http.Get(server.URL + "?tail=" + name + "&duration=" + duration)
+		if err != nil {
+			content <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		content <- string(body)
+	}
+	long, short := make(chan string, 1), make(chan string, 1)
+	go tail("400ms", long)
+	for {
+		if _, ok := l.Handler().(*tailHandler); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go tail("200ms", short)
+	for {
+		tails.Lock()
+		sinks := len(tails.handlers[l].sinks)
+		tails.Unlock()
+		if sinks == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	l.Info("tailed message")
+
+	for _, content := range []string{<-short, <-long} {
+		if !strings.Contains(content, "tailed message") {
+			t.Errorf("Expected tailed message in response, got %q", content)
+		}
+	}
+	if _, ok := l.Handler().(HandlerFunc); !ok {
+		t.Errorf("Expected original handler to be restored, got %T", l.Handler())
+	}
+	tails.Lock()
+	defer tails.Unlock()
+	if _, ok := tails.handlers[l]; ok {
+		t.Error("Expected tail handler to be removed.")
+	}
+}
+
+func TestAdminHandlerHead(t *testing.T) {
+	name := "admin." + randString()
+	l := GetLoggerOptions(name, LoggerOptions{PreventPropagation: true})
+	for target, contentType := range map[string]string{
+		"/":                               "application/json",
+		"/?tail=" + name + "&duration=1h": "text/plain; charset=utf-8",
+	} {
+		rec := httptest.NewRecorder()
+		AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodHead, target, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != contentType {
+			t.Errorf("Unexpected response to HEAD %s: %d %v", target, rec.Code, rec.Header())
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("Expected no body in response to HEAD %s, got %q", target, rec.Body.String())
+		}
+	}
+	tails.Lock()
+	_, tailed := tails.handlers[l]
+	tails.Unlock()
+	if tailed {
+		t.Error("Expected HEAD request not to attach tail handler.")
+	}
+}
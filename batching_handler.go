@@ -0,0 +1,159 @@
+package ligno
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BatchHandler processes multiple log records at once.
+type BatchHandler interface {
+	// HandleBatch processes provided records, in order in which they were
+	// logged. Slice is reused after call returns, so it must not be kept.
+	HandleBatch([]Record) error
+}
+
+// BatchHandlerFunc is function that implements BatchHandler interface.
+type BatchHandlerFunc func([]Record) error
+
+// HandleBatch just calls BatchHandlerFunc.
+func (bhf BatchHandlerFunc) HandleBatch(records []Record) error {
+	return bhf(records)
+}
+
+// WriterBatchHandler returns batch handler that formats all records in
+// batch and writes them to provided io.Writer with single Write call.
+func WriterBatchHandler(out io.Writer, formatter Formatter) BatchHandler {
+	return BatchHandlerFunc(func(records []Record) error {
+		buff := buffPool.Get()
+		defer buffPool.Put(buff)
+		for _, record := range records {
+			buff.Write(formatter.Format(record))
+		}
+		_, err := out.Write(buff.Bytes())
+		return err
+	})
+}
+
+// batchingHandler accumulates records and passes them to batch handler.
+type batchingHandler struct {
+	handler    BatchHandler
+	maxRecords int
+	maxBytes   int
+	maxLatency time.Duration
+	mu         sync.Mutex
+	batch      []Record
+	// size is estimated size of records in batch in bytes.
+	size int
+	// timer flushes batch once max latency is reached.
+	timer *time.Timer
+	// generation identifies current batch, so that timer that fired while
+	// batch was flushed does not flush next batch too early.
+	generation uint64
+}
+
+// BatchingHandler creates handler that accumulates records and passes them
+// to provided batch handler as single batch. Batch is passed when it has
+// maxRecords records, when estimated size of its records reaches maxBytes,
+// when its oldest record is older then maxLatency, when logger using
+// handler is waited for and when handler is closed. Zero value disables
+// respective limit. Size of record is estimated from length of its message
+// and context and field keys and string values.
+// Errors of batches passed because of max latency are reported right away to
+// error handler of logger that logged last record in batch, since there is
+// no caller to return them to.
+func BatchingHandler(handler BatchHandler, maxRecords, maxBytes int, maxLatency time.Duration) Handler {
+	return &batchingHandler{
+		handler:    handler,
+		maxRecords: maxRecords,
+		maxBytes:   maxBytes,
+		maxLatency: maxLatency,
+	}
+}
+
+// Handle adds record to batch and passes batch to batch handler if it
+// reached size limits.
+func (bh *batchingHandler) Handle(record Record) error {
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+	bh.batch = append(bh.batch, record.Detach())
+	bh.size += estimateRecordSize(record)
+	if (bh.maxRecords > 0 && len(bh.batch) >= bh.maxRecords) ||
+		(bh.maxBytes > 0 && bh.size >= bh.maxBytes) {
+		return bh.flush()
+	}
+	if len(bh.batch) == 1 && bh.maxLatency > 0 {
+		bh.startTimer()
+	}
+	return nil
+}
+
+// Flush passes all accumulated records to batch handler.
+func (bh *batchingHandler) Flush() error {
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+	return bh.flush()
+}
+
+// Close passes all accumulated records to batch handler and closes it, if
+// it implements HandlerCloser interface.
+func (bh *batchingHandler) Close() {
+	bh.Flush()
+	if handlerCloser, ok := bh.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// startTimer schedules flush of current batch after max latency.
+func (bh *batchingHandler) startTimer() {
+	generation := bh.generation
+	bh.timer = time.AfterFunc(bh.maxLatency, func() {
+		bh.mu.Lock()
+		if bh.generation != generation || len(bh.batch) == 0 {
+			bh.mu.Unlock()
+			return
+		}
+		record := bh.batch[len(bh.batch)-1]
+		err := bh.flush()
+		bh.mu.Unlock()
+		if err != nil {
+			record.Logger.getErrorHandler()(record, bh, err)
+		}
+	})
+}
+
+// flush passes current batch to batch handler and starts new one.
+func (bh *batchingHandler) flush() error {
+	if bh.timer != nil {
+		bh.timer.Stop()
+		bh.timer = nil
+	}
+	bh.generation++
+	if len(bh.batch) == 0 {
+		return nil
+	}
+	err := bh.handler.HandleBatch(bh.batch)
+	for i := range bh.batch {
+		bh.batch[i] = Record{}
+	}
+	bh.batch = bh.batch[:0]
+	bh.size = 0
+	return err
+}
+
+// estimateRecordSize returns approximate size of record in bytes.
+func estimateRecordSize(record Record) int {
+	size := len(record.Message)
+	for k, v := range record.Context {
+		size += len(k)
+		if s, ok := v.(string); ok {
+			size += len(s)
+		} else {
+			size += 8
+		}
+	}
+	for _, field := range record.Fields {
+		size += len(field.Key) + len(field.String) + 8
+	}
+	return size
+}
@@ -0,0 +1,96 @@
+package ligno
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingBatchHandler remembers sizes of batches it received.
+type recordingBatchHandler struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (rh *recordingBatchHandler) HandleBatch(records []Record) error {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	rh.batches = append(rh.batches, len(records))
+	return nil
+}
+
+func (rh *recordingBatchHandler) Batches() []int {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	return append([]int(nil), rh.batches...)
+}
+
+func TestBatchingHandlerSize(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	handler := BatchingHandler(inner, 3, 10, 0)
+	for _, msg := range []string{"a", "b", "c", "0123456789", "d"} {
+		handler.Handle(Record{Message: msg})
+	}
+	if batches := inner.Batches(); len(batches) != 2 || batches[0] != 3 || batches[1] != 1 {
+		t.Fatalf("Expected batches of 3 and 1 records, got %v", batches)
+	}
+	handler.(HandlerCloser).Close()
+	if batches := inner.Batches(); len(batches) != 3 || batches[2] != 1 {
+		t.Errorf("Expected remaining record to be flushed on close, got %v", batches)
+	}
+}
+
+func TestBatchingHandlerLatency(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	handler := BatchingHandler(inner, 0, 0, 10*time.Millisecond)
+	handler.Handle(Record{Message: "a"})
+	handler.Handle(Record{Message: "b"})
+	deadline := time.Now().Add(time.Second)
+	for len(inner.Batches()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if batches := inner.Batches(); len(batches) != 1 || batches[0] != 2 {
+		t.Errorf("Expected single batch with 2 records, got %v", batches)
+	}
+}
+
+func TestBatchingHandlerLoggerWait(t *testing.T) {
+	var buff bytes.Buffer
+	l := createLogger("batching", LoggerOptions{
+		Handler: BatchingHandler(WriterBatchHandler(&buff, SimpleFormat()), 100, 0, time.Hour),
+	})
+	defer l.StopAndWait()
+	l.Info("first")
+	l.Info("second")
+	l.Wait()
+	if got := bytes.Count(buff.Bytes(), []byte("\n")); got != 2 {
+		t.Errorf("Expected 2 records to be written after wait, got %d", got)
+	}
+}
+
+func TestBatchingHandlerLatencyError(t *testing.T) {
+	failed := make(chan error, 1)
+	l := createLogger("batching", LoggerOptions{
+		Handler: BatchingHandler(BatchHandlerFunc(func([]Record) error {
+			return errors.New("batch failed")
+		}), 0, 0, 10*time.Millisecond),
+		ErrorHandler: func(record Record, handler Handler, err error) {
+			if record.Message == "last" {
+				failed <- err
+			}
+		},
+	})
+	defer l.StopAndWait()
+	l.Info("first")
+	l.Info("last")
+	select {
+	case err := <-failed:
+		if err.Error() != "batch failed" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected error of flush after max latency to be reported to error handler.")
+	}
+}
@@ -85,6 +85,21 @@ func main() {
 		logFunc:   func() { ligno.Info("Ligno message") },
 		afterFunc: func() { ligno.WaitAll() },
 	})
+	results = append(results, &Measurement{
+		name: "Ligno pairs",
+		logFunc: func() {
+			ligno.Info("Ligno message", "user", "someone", "attempt", 3, "elapsed", time.Millisecond)
+		},
+		afterFunc: func() { ligno.WaitAll() },
+	})
+	results = append(results, &Measurement{
+		name: "Ligno fields",
+		logFunc: func() {
+			ligno.InfoF("Ligno message", ligno.String("user", "someone"), ligno.Int("attempt", 3),
+				ligno.Dur("elapsed", time.Millisecond))
+		},
+		afterFunc: func() { ligno.WaitAll() },
+	})
 	results = append(results, &Measurement{
 		name:    "Log15",
 		logFunc: func() { log15.Info("Log15 message") },
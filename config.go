@@ -0,0 +1,872 @@
+package ligno
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes loggers, handlers and formatters that should be
+// configured. It can be loaded from JSON or YAML document, for example:
+//
+//	formatters:
+//	  json: {type: json, options: {pretty: false}}
+//	handlers:
+//	  out: {type: stream, formatter: terminal, options: {target: stdout}}
+//	  file: {type: file, formatter: json, options: {path: /var/log/app.log}}
+//	  errors: {type: filter, level: ERROR, handler: file}
+//	  all: {type: combining, handlers: [out, errors]}
+//	loggers:
+//	  "": {level: INFO, handler: all}
+//	  payments.gateway: {level: DEBUG, context: {component: gateway}}
+type Config struct {
+	// Formatters holds formatter definitions by name.
+	Formatters map[string]FormatterConfig `json:"formatters" yaml:"formatters"`
+	// Handlers holds handler definitions by name.
+	Handlers map[string]HandlerConfig `json:"handlers" yaml:"handlers"`
+	// Loggers holds logger configurations by full logger name. Empty name
+	// denotes root logger.
+	Loggers map[string]LoggerConfig `json:"loggers" yaml:"loggers"`
+}
+
+// LoggerConfig holds configuration for single logger. Buffer size and
+// context are applied only to loggers that do not exist when configuration
+// is applied, all other values are applied to existing loggers as well.
+// Values that are not set do not change existing loggers, so configuration
+// can change only some settings of logger.
+type LoggerConfig struct {
+	// Level is name of minimal level that logger processes. If not set,
+	// level of existing logger is not changed and new logger inherits
+	// level from parent, same as with NOTSET.
+	Level string `json:"level" yaml:"level"`
+	// Handler is name of handler from Handlers. If empty, handler of
+	// existing logger is not changed.
+	Handler string `json:"handler" yaml:"handler"`
+	// PreventPropagation is flag that indicates if records should not be
+	// passed to parent logger.
+	PreventPropagation *bool `json:"prevent_propagation" yaml:"prevent_propagation"`
+	// BufferSize is size of buffer for records.
+	BufferSize int `json:"buffer_size" yaml:"buffer_size"`
+	// Context is context of logger.
+	Context Ctx `json:"context" yaml:"context"`
+	// IncludeFileAndLine is flag that indicates if file and line where
+	// logging took place should be kept.
+	IncludeFileAndLine *bool `json:"include_file_and_line" yaml:"include_file_and_line"`
+	// StackTraceLevel is name of lowest level for which stack trace is
+	// captured. NOTSET disables capturing.
+	StackTraceLevel string `json:"stack_trace_level" yaml:"stack_trace_level"`
+	// Synchronous is flag that indicates if records should be processed on
+	// calling goroutine. If not set, mode of logger is not changed.
+	Synchronous *bool `json:"synchronous" yaml:"synchronous"`
+}
+
+// HandlerConfig holds definition of single handler. Type selects factory
+// registered with RegisterHandlerType, which interprets other values.
+type HandlerConfig struct {
+	// Type is name of registered handler type.
+	Type string `json:"type" yaml:"type"`
+	// Formatter is name of formatter from Formatters or name of registered
+	// formatter type, which is then created with default options.
+	Formatter string `json:"formatter" yaml:"formatter"`
+	// Level is used by handlers that filter records by level.
+	Level string `json:"level" yaml:"level"`
+	// Handler is name of handler wrapped by this handler.
+	Handler string `json:"handler" yaml:"handler"`
+	// Handlers is list of names of handlers wrapped by this handler.
+	Handlers []string `json:"handlers" yaml:"handlers"`
+	// Options holds handler type specific options.
+	Options map[string]interface{} `json:"options" yaml:"options"`
+}
+
+// DecodeOptions decodes handler options into provided value, using its
+// JSON struct tags.
+func (hc HandlerConfig) DecodeOptions(v interface{}) error {
+	return decodeOptions(hc.Options, v)
+}
+
+// FormatterConfig holds definition of single formatter. Type selects
+// factory registered with RegisterFormatterType, which interprets options.
+type FormatterConfig struct {
+	// Type is name of registered formatter type.
+	Type string `json:"type" yaml:"type"`
+	// Options holds formatter type specific options.
+	Options map[string]interface{} `json:"options" yaml:"options"`
+}
+
+// DecodeOptions decodes formatter options into provided value, using its
+// JSON struct tags.
+func (fc FormatterConfig) DecodeOptions(v interface{}) error {
+	return decodeOptions(fc.Options, v)
+}
+
+// decodeOptions decodes options into provided value by converting them to
+// JSON, since options loaded from JSON and YAML documents have different
+// types for same values (numbers for example).
+func decodeOptions(options map[string]interface{}, v interface{}) error {
+	if len(options) == 0 {
+		return nil
+	}
+	marshaled, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(marshaled))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// ConfigRefs resolves handlers and formatters referenced by name from
+// handler configuration.
+type ConfigRefs interface {
+	// Handler returns handler with provided name.
+	Handler(name string) (Handler, error)
+	// Formatter returns formatter with provided name. Empty name returns
+	// default formatter.
+	Formatter(name string) (Formatter, error)
+}
+
+// HandlerFactory creates handler from its configuration.
+type HandlerFactory func(config HandlerConfig, refs ConfigRefs) (Handler, error)
+
+// FormatterFactory creates formatter from its configuration.
+type FormatterFactory func(config FormatterConfig) (Formatter, error)
+
+// registry holds registered handler and formatter types.
+var registry struct {
+	sync.RWMutex
+	handlers   map[string]HandlerFactory
+	formatters map[string]FormatterFactory
+}
+
+// RegisterHandlerType registers factory for handlers of provided type, so
+// that they can be used in configuration.
+// It is forbidden to register types that already exist.
+func RegisterHandlerType(name string, factory HandlerFactory) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.handlers == nil {
+		registry.handlers = make(map[string]HandlerFactory)
+	}
+	if _, ok := registry.handlers[name]; ok {
+		return fmt.Errorf("handler type '%s' already exists", name)
+	}
+	registry.handlers[name] = factory
+	return nil
+}
+
+// RegisterFormatterType registers factory for formatters of provided type,
+// so that they can be used in configuration.
+// It is forbidden to register types that already exist.
+func RegisterFormatterType(name string, factory FormatterFactory) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.formatters == nil {
+		registry.formatters = make(map[string]FormatterFactory)
+	}
+	if _, ok := registry.formatters[name]; ok {
+		return fmt.Errorf("formatter type '%s' already exists", name)
+	}
+	registry.formatters[name] = factory
+	return nil
+}
+
+// LoadConfig reads configuration from provided reader. Document can be
+// in JSON or YAML format.
+func LoadConfig(r io.Reader) (Config, error) {
+	var config Config
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return config, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&config)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(&config); err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return config, fmt.Errorf("invalid logging configuration: %v", err)
+	}
+	return config, nil
+}
+
+// ConfigureFromFile loads configuration from file with provided path and
+// applies it. See Configure for details.
+func ConfigureFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ConfigureFromReader(f)
+}
+
+// ConfigureFromReader loads configuration from provided reader and applies
+// it. See Configure for details.
+func ConfigureFromReader(r io.Reader) error {
+	config, err := LoadConfig(r)
+	if err != nil {
+		return err
+	}
+	return Configure(config)
+}
+
+// Configure builds handlers from provided configuration and applies them,
+// together with other options, to configured loggers, creating loggers
+// that do not exist. Configuration is validated and all handlers are
+// created before any logger is changed, so invalid configuration does not
+// leave logger tree partially configured.
+// Handlers that are replaced and no longer used by any logger are closed
+// (if they implement HandlerCloser) after record that is being processed by
+// them, if any, is handled.
+func Configure(config Config) error {
+	built, err := buildConfig(config)
+	if err != nil {
+		return err
+	}
+	replaced := built.apply()
+	closeReplacedHandlers(replaced)
+	return nil
+}
+
+// replacedHandler holds handler that was replaced in logger.
+type replacedHandler struct {
+	logger  *Logger
+	handler Handler
+}
+
+// closeReplacedHandlers waits for loggers to finish handling records that
+// might be processed by replaced handlers and closes handlers that are not
+// used by any logger any more.
+func closeReplacedHandlers(replaced []replacedHandler) {
+	if len(replaced) == 0 {
+		return
+	}
+	var inUse []Handler
+	rootLogger.walk(func(l *Logger) {
+		inUse = append(inUse, l.Handler())
+	})
+	var closed []Handler
+	for _, r := range replaced {
+		// handler is resolved for every record while handling lock is held,
+		// so once lock is acquired, replaced handler is not used any more
+		// and records that are still queued go to new handler
+		r.logger.handling.Lock()
+		r.logger.handling.Unlock()
+		closer, ok := r.handler.(HandlerCloser)
+		if !ok || containsHandler(inUse, r.handler) || containsHandler(closed, r.handler) {
+			continue
+		}
+		closer.Close()
+		closed = append(closed, r.handler)
+	}
+}
+
+// containsHandler returns true if provided handler is in slice of handlers.
+// Handlers of types that are not comparable are never considered equal.
+func containsHandler(handlers []Handler, handler Handler) bool {
+	return handlerIndex(handlers, handler) >= 0
+}
+
+// handlerIndex returns index of provided handler in slice of handlers, or
+// -1 if it is not there. Handlers of types that are not comparable are
+// never considered equal.
+func handlerIndex(handlers []Handler, handler Handler) int {
+	if handler == nil || !reflect.TypeOf(handler).Comparable() {
+		return -1
+	}
+	for i, h := range handlers {
+		if h != nil && reflect.TypeOf(h) == reflect.TypeOf(handler) && h == handler {
+			return i
+		}
+	}
+	return -1
+}
+
+// builtLogger holds logger configuration with all values parsed.
+type builtLogger struct {
+	name            string
+	config          LoggerConfig
+	level           Level
+	stackTraceLevel Level
+	handler         Handler
+}
+
+// builtConfig holds configuration with all handlers created, ready to be
+// applied to logger tree.
+type builtConfig struct {
+	loggers []builtLogger
+}
+
+// configBuilder creates handlers and formatters from configuration,
+// resolving references between them.
+type configBuilder struct {
+	config     Config
+	handlers   map[string]Handler
+	formatters map[string]Formatter
+	// building holds names of handlers that are currently being built,
+	// used for detecting reference cycles.
+	building map[string]bool
+}
+
+// buildConfig validates configuration and creates all handlers it defines.
+func buildConfig(config Config) (*builtConfig, error) {
+	b := &configBuilder{
+		config:     config,
+		handlers:   make(map[string]Handler),
+		formatters: make(map[string]Formatter),
+		building:   make(map[string]bool),
+	}
+	built, err := b.build()
+	if err != nil {
+		b.closeHandlers()
+		return nil, err
+	}
+	return built, nil
+}
+
+// build creates all handlers and parses logger configurations.
+func (b *configBuilder) build() (*builtConfig, error) {
+	names := make([]string, 0, len(b.config.Handlers))
+	for name := range b.config.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := b.Handler(name); err != nil {
+			return nil, err
+		}
+	}
+
+	built := &builtConfig{}
+	for name, loggerConfig := range b.config.Loggers {
+		bl := builtLogger{name: name, config: loggerConfig}
+		var err error
+		if loggerConfig.Level != "" {
+			if bl.level, err = ParseLevel(loggerConfig.Level); err != nil {
+				return nil, fmt.Errorf("logger '%s': %v", name, err)
+			}
+		}
+		if loggerConfig.StackTraceLevel != "" {
+			if bl.stackTraceLevel, err = ParseLevel(loggerConfig.StackTraceLevel); err != nil {
+				return nil, fmt.Errorf("logger '%s': %v", name, err)
+			}
+		}
+		if loggerConfig.Handler != "" {
+			if bl.handler, err = b.Handler(loggerConfig.Handler); err != nil {
+				return nil, fmt.Errorf("logger '%s': %v", name, err)
+			}
+		}
+		built.loggers = append(built.loggers, bl)
+	}
+	// configure parents before children, so that intermediate loggers get
+	// their options instead of defaults
+	sort.Slice(built.loggers, func(i, j int) bool {
+		di, dj := loggerDepth(built.loggers[i].name), loggerDepth(built.loggers[j].name)
+		if di != dj {
+			return di < dj
+		}
+		return built.loggers[i].name < built.loggers[j].name
+	})
+	return built, nil
+}
+
+// loggerDepth returns depth of logger with provided full name in logger tree.
+func loggerDepth(name string) int {
+	if name == "" {
+		return 0
+	}
+	return strings.Count(name, ".") + 1
+}
+
+// Handler returns handler with provided name, building it if needed.
+func (b *configBuilder) Handler(name string) (Handler, error) {
+	if handler, ok := b.handlers[name]; ok {
+		return handler, nil
+	}
+	config, ok := b.config.Handlers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown handler '%s'", name)
+	}
+	if b.building[name] {
+		return nil, fmt.Errorf("handler '%s' references itself", name)
+	}
+	b.building[name] = true
+	defer delete(b.building, name)
+
+	registry.RLock()
+	factory, ok := registry.handlers[config.Type]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("handler '%s': unknown handler type '%s'", name, config.Type)
+	}
+	handler, err := factory(config, b)
+	if err != nil {
+		return nil, fmt.Errorf("handler '%s': %v", name, err)
+	}
+	b.handlers[name] = handler
+	return handler, nil
+}
+
+// Formatter returns formatter with provided name, building it if needed.
+func (b *configBuilder) Formatter(name string) (Formatter, error) {
+	if name == "" {
+		return TerminalFormat(), nil
+	}
+	if formatter, ok := b.formatters[name]; ok {
+		return formatter, nil
+	}
+	config, ok := b.config.Formatters[name]
+	if !ok {
+		// allow referencing formatter types directly
+		config = FormatterConfig{Type: name}
+	}
+	registry.RLock()
+	factory, ok := registry.formatters[config.Type]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown formatter '%s'", name)
+	}
+	formatter, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("formatter '%s': %v", name, err)
+	}
+	b.formatters[name] = formatter
+	return formatter, nil
+}
+
+// closeHandlers closes all handlers that were built.
+func (b *configBuilder) closeHandlers() {
+	for _, handler := range b.handlers {
+		if closer, ok := handler.(HandlerCloser); ok {
+			closer.Close()
+		}
+	}
+}
+
+// apply applies configuration to logger tree and returns handlers that
+// were replaced.
+func (bc *builtConfig) apply() (replaced []replacedHandler) {
+	for _, bl := range bc.loggers {
+		l, exists := findLogger(bl.name)
+		if !exists {
+			l = GetLoggerOptions(bl.name, LoggerOptions{
+				Context:            bl.config.Context,
+				Handler:            bl.handler,
+				Level:              bl.level,
+				BufferSize:         bl.config.BufferSize,
+				PreventPropagation: bl.config.PreventPropagation != nil && *bl.config.PreventPropagation,
+				IncludeFileAndLine: bl.config.IncludeFileAndLine != nil && *bl.config.IncludeFileAndLine,
+				StackTraceLevel:    bl.stackTraceLevel,
+			})
+			if bl.config.Synchronous != nil {
+				l.SetSynchronous(*bl.config.Synchronous)
+			}
+			continue
+		}
+		if bl.config.Level != "" {
+			l.SetLevel(bl.level)
+		}
+		if bl.config.PreventPropagation != nil {
+			l.SetPreventPropagation(*bl.config.PreventPropagation)
+		}
+		if bl.config.IncludeFileAndLine != nil {
+			l.SetIncludeFileAndLine(*bl.config.IncludeFileAndLine)
+		}
+		if bl.config.StackTraceLevel != "" {
+			l.SetStackTraceLevel(bl.stackTraceLevel)
+		}
+		if bl.config.Synchronous != nil {
+			l.SetSynchronous(*bl.config.Synchronous)
+		}
+		if bl.handler != nil {
+			if old := l.Handler(); old != nil {
+				replaced = append(replaced, replacedHandler{logger: l, handler: old})
+			}
+			l.SetHandler(bl.handler)
+		}
+	}
+	return replaced
+}
+
+// levelOrDefault parses level name, returning provided default level if
+// name is empty.
+func levelOrDefault(name string, def Level) (Level, error) {
+	if name == "" {
+		return def, nil
+	}
+	return ParseLevel(name)
+}
+
+func init() {
+	RegisterFormatterType("simple", func(FormatterConfig) (Formatter, error) {
+		return SimpleFormat(), nil
+	})
+	RegisterFormatterType("terminal", func(config FormatterConfig) (Formatter, error) {
+		var options struct {
+			Color *bool `json:"color"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		if options.Color == nil {
+			return TerminalFormat(), nil
+		}
+		if *options.Color {
+			return ThemedTerminalFormat(DefaultTheme), nil
+		}
+		return ThemedTerminalFormat(NoColorTheme), nil
+	})
+	RegisterFormatterType("json", func(config FormatterConfig) (Formatter, error) {
+		var options struct {
+			Pretty bool `json:"pretty"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		return JSONFormat(options.Pretty), nil
+	})
+	RegisterFormatterType("logfmt", func(config FormatterConfig) (Formatter, error) {
+		var options struct {
+			TimeFormat string `json:"time_format"`
+			TimeKey    string `json:"time_key"`
+			LevelKey   string `json:"level_key"`
+			MessageKey string `json:"message_key"`
+			LoggerKey  string `json:"logger_key"`
+			CallerKey  string `json:"caller_key"`
+			StackKey   string `json:"stack_key"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		return LogfmtFormat(LogfmtOptions(options)), nil
+	})
+
+	RegisterHandlerType("null", func(HandlerConfig, ConfigRefs) (Handler, error) {
+		return NullHandler(), nil
+	})
+	RegisterHandlerType("stream", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Target string `json:"target"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		formatter, err := refs.Formatter(config.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		switch options.Target {
+		case "", "stdout":
+			return StreamHandler(os.Stdout, formatter), nil
+		case "stderr":
+			return StreamHandler(os.Stderr, formatter), nil
+		default:
+			return nil, fmt.Errorf("unknown stream target '%s'", options.Target)
+		}
+	})
+	RegisterHandlerType("file", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Path       string `json:"path"`
+			MaxSize    int64  `json:"max_size"`
+			Interval   string `json:"interval"`
+			MaxBackups int    `json:"max_backups"`
+			Compress   bool   `json:"compress"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		if options.Path == "" {
+			return nil, fmt.Errorf("path is required")
+		}
+		formatter, err := refs.Formatter(config.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		if options.MaxSize == 0 && options.Interval == "" {
+			return FileHandler(options.Path, formatter), nil
+		}
+		rotation := RotationOptions{
+			MaxSize:    options.MaxSize,
+			MaxBackups: options.MaxBackups,
+			Compress:   options.Compress,
+		}
+		switch options.Interval {
+		case "":
+		case "hourly":
+			rotation.Interval = RotateHourly
+		case "daily":
+			rotation.Interval = RotateDaily
+		default:
+			if rotation.Interval, err = time.ParseDuration(options.Interval); err != nil {
+				return nil, fmt.Errorf("invalid interval: %v", err)
+			}
+		}
+		return RotatingFileHandler(options.Path, formatter, rotation), nil
+	})
+	RegisterHandlerType("net", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Network    string `json:"network"`
+			Address    string `json:"address"`
+			Framing    string `json:"framing"`
+			SpoolSize  int    `json:"spool_size"`
+			MaxBackoff string `json:"max_backoff"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		if options.Network == "" || options.Address == "" {
+			return nil, fmt.Errorf("network and address are required")
+		}
+		formatter, err := refs.Formatter(config.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		netOptions := NetOptions{SpoolSize: options.SpoolSize}
+		switch options.Framing {
+		case "", "none":
+		case "newline":
+			netOptions.Framing = FramingNewline
+		case "octet_count":
+			netOptions.Framing = FramingOctetCount
+		default:
+			return nil, fmt.Errorf("unknown framing '%s'", options.Framing)
+		}
+		if options.MaxBackoff != "" {
+			if netOptions.MaxBackoff, err = time.ParseDuration(options.MaxBackoff); err != nil {
+				return nil, fmt.Errorf("invalid max_backoff: %v", err)
+			}
+		}
+		return NetHandler(options.Network, options.Address, formatter, netOptions), nil
+	})
+	RegisterHandlerType("remote_syslog", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Network    string           `json:"network"`
+			Address    string           `json:"address"`
+			Format     string           `json:"format"`
+			Facility   int              `json:"facility"`
+			Hostname   string           `json:"hostname"`
+			AppName    string           `json:"app_name"`
+			Severities map[string]uint8 `json:"severities"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		if options.Network == "" || options.Address == "" {
+			return nil, fmt.Errorf("network and address are required")
+		}
+		var formatter Formatter
+		if config.Formatter != "" {
+			var err error
+			if formatter, err = refs.Formatter(config.Formatter); err != nil {
+				return nil, err
+			}
+		}
+		syslogOptions := SyslogOptions{
+			Facility: SyslogFacility(options.Facility),
+			Hostname: options.Hostname,
+			AppName:  options.AppName,
+		}
+		switch strings.ToLower(options.Format) {
+		case "", "rfc5424":
+		case "rfc3164":
+			syslogOptions.Format = SyslogRFC3164
+		default:
+			return nil, fmt.Errorf("unknown syslog format '%s'", options.Format)
+		}
+		if len(options.Severities) > 0 {
+			syslogOptions.Severities = make(map[Level]SyslogSeverity, len(options.Severities))
+			for name, severity := range options.Severities {
+				level, err := ParseLevel(name)
+				if err != nil {
+					return nil, err
+				}
+				syslogOptions.Severities[level] = SyslogSeverity(severity)
+			}
+		}
+		return RemoteSyslogHandler(options.Network, options.Address, formatter, syslogOptions), nil
+	})
+	RegisterHandlerType("batching", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Target     string `json:"target"`
+			MaxRecords int    `json:"max_records"`
+			MaxBytes   int    `json:"max_bytes"`
+			MaxLatency string `json:"max_latency"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		formatter, err := refs.Formatter(config.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		var batchHandler BatchHandler
+		switch options.Target {
+		case "", "stdout":
+			batchHandler = WriterBatchHandler(os.Stdout, formatter)
+		case "stderr":
+			batchHandler = WriterBatchHandler(os.Stderr, formatter)
+		default:
+			return nil, fmt.Errorf("unknown stream target '%s'", options.Target)
+		}
+		var maxLatency time.Duration
+		if options.MaxLatency != "" {
+			if maxLatency, err = time.ParseDuration(options.MaxLatency); err != nil {
+				return nil, fmt.Errorf("invalid max_latency: %v", err)
+			}
+		}
+		return BatchingHandler(batchHandler, options.MaxRecords, options.MaxBytes, maxLatency), nil
+	})
+	RegisterHandlerType("filter", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		level, err := levelOrDefault(config.Level, NOTSET)
+		if err != nil {
+			return nil, err
+		}
+		handler, err := refs.Handler(config.Handler)
+		if err != nil {
+			return nil, err
+		}
+		return FilterLevelHandler(level, handler), nil
+	})
+	RegisterHandlerType("sampling", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Interval    string  `json:"interval"`
+			First       int     `json:"first"`
+			Thereafter  int     `json:"thereafter"`
+			Probability float64 `json:"probability"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		policy := SamplingPolicy{
+			First:       options.First,
+			Thereafter:  options.Thereafter,
+			Probability: options.Probability,
+		}
+		if options.Interval != "" {
+			var err error
+			if policy.Interval, err = time.ParseDuration(options.Interval); err != nil {
+				return nil, fmt.Errorf("invalid interval: %v", err)
+			}
+		}
+		handler, err := refs.Handler(config.Handler)
+		if err != nil {
+			return nil, err
+		}
+		return SamplingHandler(handler, policy), nil
+	})
+	RegisterHandlerType("fingers_crossed", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Trigger    string `json:"trigger"`
+			BufferSize int    `json:"buffer_size"`
+			KeyField   string `json:"key_field"`
+			ResetAfter string `json:"reset_after"`
+			MaxKeys    int    `json:"max_keys"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		trigger, err := levelOrDefault(options.Trigger, ERROR)
+		if err != nil {
+			return nil, err
+		}
+		fingersCrossed := FingersCrossedOptions{
+			KeyField: options.KeyField,
+			MaxKeys:  options.MaxKeys,
+		}
+		if options.ResetAfter != "" {
+			if fingersCrossed.ResetAfter, err = time.ParseDuration(options.ResetAfter); err != nil {
+				return nil, fmt.Errorf("invalid reset_after: %v", err)
+			}
+		}
+		handler, err := refs.Handler(config.Handler)
+		if err != nil {
+			return nil, err
+		}
+		return FingersCrossedHandlerOptions(trigger, options.BufferSize, handler, fingersCrossed), nil
+	})
+	RegisterHandlerType("redacting", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Keys           []string `json:"keys"`
+			KeyPatterns    []string `json:"key_patterns"`
+			ValuePatterns  []string `json:"value_patterns"`
+			Strategy       string   `json:"strategy"`
+			Replacement    string   `json:"replacement"`
+			HashKey        string   `json:"hash_key"`
+			TruncateLength int      `json:"truncate_length"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		redact := RedactOptions{
+			Keys:           options.Keys,
+			Replacement:    options.Replacement,
+			HashKey:        []byte(options.HashKey),
+			TruncateLength: options.TruncateLength,
+		}
+		switch options.Strategy {
+		case "", "replace":
+		case "hash":
+			redact.Strategy = RedactHash
+		case "truncate":
+			redact.Strategy = RedactTruncate
+		default:
+			return nil, fmt.Errorf("unknown redact strategy '%s'", options.Strategy)
+		}
+		for _, pattern := range options.KeyPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key pattern: %v", err)
+			}
+			redact.KeyPatterns = append(redact.KeyPatterns, re)
+		}
+		for _, pattern := range options.ValuePatterns {
+			// well known patterns can be referenced by name
+			switch pattern {
+			case "credit_card":
+				redact.ValuePatterns = append(redact.ValuePatterns, CreditCardPattern)
+			case "jwt":
+				redact.ValuePatterns = append(redact.ValuePatterns, JWTPattern)
+			case "email":
+				redact.ValuePatterns = append(redact.ValuePatterns, EmailPattern)
+			default:
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value pattern: %v", err)
+				}
+				redact.ValuePatterns = append(redact.ValuePatterns, re)
+			}
+		}
+		handler, err := refs.Handler(config.Handler)
+		if err != nil {
+			return nil, err
+		}
+		return RedactingHandler(NewRedactor(redact), handler), nil
+	})
+	RegisterHandlerType("combining", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		handlers := make([]Handler, 0, len(config.Handlers))
+		for _, name := range config.Handlers {
+			handler, err := refs.Handler(name)
+			if err != nil {
+				return nil, err
+			}
+			handlers = append(handlers, handler)
+		}
+		return CombiningHandler(handlers...), nil
+	})
+}
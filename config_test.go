@@ -0,0 +1,197 @@
+package ligno
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfigureFromReaderYAML(t *testing.T) {
+	name := "config." + randString()
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	doc := `
+formatters:
+  plain: {type: logfmt, options: {time_key: "-"}}
+handlers:
+  file: {type: file, formatter: plain, options: {path: "` + logFile + `"}}
+  errors: {type: filter, level: ERROR, handler: file}
+loggers:
+  ` + name + `: {level: INFO, handler: errors, prevent_propagation: true}
+  ` + name + `.child: {level: DEBUG, context: {component: child}}
+`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	l, ok := findLogger(name)
+	if !ok {
+		t.Fatal("Expected logger to be created.")
+	}
+	child := GetLogger(name + ".child")
+	if l.Level() != INFO || !l.PreventPropagation() || child.Level() != DEBUG {
+		t.Errorf("Unexpected logger configuration: %s, %s", l.Level(), child.Level())
+	}
+	l.Warning("filtered")
+	child.Error("written")
+	l.Wait()
+	l.Handler().(HandlerCloser).Close()
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "level=ERROR msg=written logger=" + name + ".child component=child\n"
+	if string(content) != expected {
+		t.Errorf("Unexpected file content: %q, expected %q", content, expected)
+	}
+}
+
+func TestConfigureFromReaderJSON(t *testing.T) {
+	name := "config." + randString()
+	var handled []string
+	RegisterHandlerType("test-"+name, func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Prefix string `json:"prefix"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		return HandlerFunc(func(record Record) error {
+			handled = append(handled, options.Prefix+record.Message)
+			return nil
+		}), nil
+	})
+	doc := `{
+	"handlers": {"custom": {"type": "test-` + name + `", "options": {"prefix": "> "}}},
+	"loggers": {"` + name + `": {"handler": "custom", "prevent_propagation": true}}
+}`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	l := GetLogger(name)
+	l.Info("message")
+	l.Wait()
+	if len(handled) != 1 || handled[0] != "> message" {
+		t.Errorf("Expected record to be handled by custom handler, got %v", handled)
+	}
+}
+
+func TestConfigureInvalid(t *testing.T) {
+	name := "config." + randString()
+	for _, doc := range []string{
+		`loggers: {` + name + `: {level: BOGUS}}`,
+		`loggers: {` + name + `: {handler: missing}}`,
+		`handlers: {h: {type: missing}}`,
+		`handlers: {a: {type: filter, handler: b}, b: {type: filter, handler: a}}`,
+		`handlers: {h: {type: stream, options: {unknown: true}}}`,
+		`unknown: true`,
+	} {
+		if err := ConfigureFromReader(strings.NewReader(doc)); err == nil {
+			t.Errorf("Expected error for configuration: %s", doc)
+		}
+	}
+	if _, ok := findLogger(name); ok {
+		t.Error("Expected invalid configuration not to create loggers.")
+	}
+}
+
+func TestConfigureExistingLogger(t *testing.T) {
+	name := "config." + randString()
+	l := GetLoggerOptions(name, LoggerOptions{Handler: NullHandler(), PreventPropagation: true})
+	defer l.StopAndWait()
+	doc := `
+loggers:
+  ` + name + `: {level: WARNING, prevent_propagation: true, include_file_and_line: true, stack_trace_level: ERROR}
+`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if l.Level() != WARNING || !l.IncludeFileAndLine() || l.StackTraceLevel() != ERROR {
+		t.Errorf("Expected configuration to be applied to existing logger, got %s, %t, %s",
+			l.Level(), l.IncludeFileAndLine(), l.StackTraceLevel())
+	}
+
+	// configuration that only replaces handler keeps other settings
+	doc = `
+handlers: {out: {type: stream, options: {target: stdout}}}
+loggers: {` + name + `: {handler: out}}
+`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if l.Level() != WARNING || !l.PreventPropagation() || !l.IncludeFileAndLine() || l.StackTraceLevel() != ERROR {
+		t.Errorf("Expected settings not present in configuration to be kept, got %s, %t, %t, %s",
+			l.Level(), l.PreventPropagation(), l.IncludeFileAndLine(), l.StackTraceLevel())
+	}
+	if _, ok := l.Handler().(HandlerFunc); !ok {
+		t.Errorf("Expected handler to be replaced, got %T", l.Handler())
+	}
+
+	// NOTSET explicitly resets levels
+	doc = `loggers: {` + name + `: {level: NOTSET, stack_trace_level: NOTSET, prevent_propagation: false}}`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if l.Level() != NOTSET || l.StackTraceLevel() != NOTSET || l.PreventPropagation() {
+		t.Errorf("Expected explicitly set values to be applied, got %s, %s, %t",
+			l.Level(), l.StackTraceLevel(), l.PreventPropagation())
+	}
+}
+
+func TestConfigureUnderLoad(t *testing.T) {
+	name := "config." + randString()
+	slow := HandlerFunc(func(Record) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	old := new(closeTrackingHandler)
+	l := GetLoggerOptions(name, LoggerOptions{
+		Handler:            CombiningHandler(slow, old),
+		BufferSize:         16,
+		PreventPropagation: true,
+	})
+	defer l.StopAndWait()
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				l.Info("steady traffic")
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	RegisterHandlerType("slow-"+name, func(HandlerConfig, ConfigRefs) (Handler, error) {
+		return slow, nil
+	})
+	doc := `
+handlers: {slow: {type: slow-` + name + `}}
+loggers: {` + name + `: {handler: slow, prevent_propagation: true}}
+`
+	done := make(chan error, 1)
+	go func() {
+		done <- ConfigureFromReader(strings.NewReader(doc))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected configuration to be applied while logger is busy.")
+	}
+	if atomic.LoadInt32(&old.closed) != 1 {
+		t.Error("Expected replaced handler to be closed.")
+	}
+}
@@ -0,0 +1,139 @@
+package ligno
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// defaultWatchInterval is interval in which configuration file is checked
+// for changes if interval is not set in watch options.
+const defaultWatchInterval = 5 * time.Second
+
+// WatchOptions holds configuration for WatchConfigFile.
+type WatchOptions struct {
+	// Interval is interval in which modification time of configuration file
+	// is checked. Zero means default of 5 seconds, while negative value
+	// disables polling, so configuration is reloaded only on signals.
+	Interval time.Duration
+	// Signals is list of signals that trigger reload of configuration,
+	// usually syscall.SIGHUP.
+	Signals []os.Signal
+	// OnReload is called after every reload attempt, with error that
+	// occurred or nil if new configuration is applied. If not set, errors
+	// are written to standard error output.
+	OnReload func(err error)
+}
+
+// ConfigWatcher reloads logging configuration from file when it changes.
+type ConfigWatcher struct {
+	path    string
+	options WatchOptions
+	// mu prevents concurrent reloads.
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// WatchConfigFile configures logging from file with provided path and
+// starts watching it for changes. When file changes (or one of configured
+// signals is received), configuration is loaded again and applied.
+// Invalid configuration is reported and previous configuration is kept.
+// Error is returned only if initial configuration can not be applied.
+func WatchConfigFile(path string, options WatchOptions) (*ConfigWatcher, error) {
+	if options.Interval == 0 {
+		options.Interval = defaultWatchInterval
+	}
+	w := &ConfigWatcher{
+		path:    path,
+		options: options,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	go w.watch()
+	return w, nil
+}
+
+// Reload loads configuration from file and applies it.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// remember state of file before reading, so that change that happens
+	// while reading is not missed
+	if info, err := os.Stat(w.path); err == nil {
+		w.modTime = info.ModTime()
+		w.size = info.Size()
+	}
+	return ConfigureFromFile(w.path)
+}
+
+// Stop stops watching configuration file. Current configuration stays
+// in effect.
+func (w *ConfigWatcher) Stop() {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	<-w.done
+}
+
+// changed returns true if configuration file changed since it was last loaded.
+func (w *ConfigWatcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+// watch reloads configuration when file changes or signal is received,
+// until watcher is stopped.
+func (w *ConfigWatcher) watch() {
+	defer close(w.done)
+	var tick <-chan time.Time
+	if w.options.Interval > 0 {
+		ticker := time.NewTicker(w.options.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	var signals chan os.Signal
+	if len(w.options.Signals) > 0 {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, w.options.Signals...)
+		defer signal.Stop(signals)
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-tick:
+			if w.changed() {
+				w.reported(w.Reload())
+			}
+		case <-signals:
+			w.reported(w.Reload())
+		}
+	}
+}
+
+// reported passes result of reload to OnReload function from options, or
+// writes error to standard error output if it is not set.
+func (w *ConfigWatcher) reported(err error) {
+	if w.options.OnReload != nil {
+		w.options.OnReload(err)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ligno: failed to reload logging configuration from %s: %v\n", w.path, err)
+	}
+}
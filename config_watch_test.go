@@ -0,0 +1,80 @@
+package ligno
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeTrackingHandler is handler that counts how many times it was closed.
+type closeTrackingHandler struct {
+	closed int32
+}
+
+func (h *closeTrackingHandler) Handle(Record) error {
+	return nil
+}
+
+func (h *closeTrackingHandler) Close() {
+	atomic.AddInt32(&h.closed, 1)
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	name := "watch." + randString()
+	var created []*closeTrackingHandler
+	RegisterHandlerType("tracking-"+name, func(HandlerConfig, ConfigRefs) (Handler, error) {
+		h := new(closeTrackingHandler)
+		created = append(created, h)
+		return h, nil
+	})
+	path := filepath.Join(t.TempDir(), "logging.yaml")
+	write := func(content string, mtime time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := func(level string) string {
+		return "handlers: {h: {type: tracking-" + name + "}}\n" +
+			"loggers: {" + name + ": {level: " + level + ", handler: h}}\n"
+	}
+	start := time.Now().Add(-time.Hour)
+	write(config("INFO"), start)
+
+	reloaded := make(chan error, 10)
+	watcher, err := WatchConfigFile(path, WatchOptions{
+		Interval: 5 * time.Millisecond,
+		OnReload: func(err error) { reloaded <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+	l := GetLogger(name)
+	if l.Level() != INFO {
+		t.Fatalf("Expected initial configuration to be applied, got level %s", l.Level())
+	}
+
+	write(config("DEBUG"), start.Add(time.Minute))
+	if err := <-reloaded; err != nil {
+		t.Fatal(err)
+	}
+	if l.Level() != DEBUG {
+		t.Errorf("Expected reloaded configuration to be applied, got level %s", l.Level())
+	}
+	if len(created) != 2 || atomic.LoadInt32(&created[0].closed) != 1 || atomic.LoadInt32(&created[1].closed) != 0 {
+		t.Error("Expected replaced handler to be closed.")
+	}
+
+	write(config("BOGUS"), start.Add(2*time.Minute))
+	if err := <-reloaded; err == nil {
+		t.Error("Expected error for invalid configuration.")
+	}
+	if l.Level() != DEBUG || l.Handler() != Handler(created[1]) {
+		t.Error("Expected previous configuration to be kept.")
+	}
+}
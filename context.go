@@ -0,0 +1,148 @@
+package ligno
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// loggerContextKey is key under which logger is stored in context.Context.
+type loggerContextKey struct{}
+
+// NewContext returns copy of provided context that carries provided logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns logger stored in provided context. If context does
+// not carry logger, root logger is returned.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return rootLogger
+}
+
+// ContextExtractor is function that extracts values from context.Context
+// that should be added to records logged with that context (like request
+// or trace IDs).
+type ContextExtractor func(ctx context.Context) Ctx
+
+// contextExtractors holds all registered context extractors. Pointers are
+// stored, since functions can not be compared when extractor is removed.
+var contextExtractors struct {
+	sync.RWMutex
+	list []*ContextExtractor
+}
+
+// RegisterContextExtractor registers extractor that will be used for
+// extracting values from context.Context for all records logged with one
+// of *Context methods. Values from extractors registered later override
+// values from extractors registered earlier.
+// Returned function removes extractor, it is safe to call it multiple times.
+func RegisterContextExtractor(extractor ContextExtractor) (remove func()) {
+	registered := &extractor
+	contextExtractors.Lock()
+	defer contextExtractors.Unlock()
+	contextExtractors.list = append(contextExtractors.list, registered)
+	return func() {
+		contextExtractors.Lock()
+		defer contextExtractors.Unlock()
+		for i, e := range contextExtractors.list {
+			if e == registered {
+				contextExtractors.list = append(contextExtractors.list[:i], contextExtractors.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ContextKeyExtractor returns extractor that adds value stored in
+// context.Context under provided key to records with provided name.
+// Nothing is added if context does not hold value for key.
+func ContextKeyExtractor(key interface{}, name string) ContextExtractor {
+	return func(ctx context.Context) Ctx {
+		if v := ctx.Value(key); v != nil {
+			return Ctx{name: v}
+		}
+		return nil
+	}
+}
+
+// extractContext adds values from all registered extractors to provided
+// logging context.
+func extractContext(ctx context.Context, into Ctx) {
+	if ctx == nil {
+		return
+	}
+	contextExtractors.RLock()
+	defer contextExtractors.RUnlock()
+	for _, extractor := range contextExtractors.list {
+		for k, v := range (*extractor)(ctx) {
+			into[k] = v
+		}
+	}
+}
+
+// LogContext creates record and queues it for processing. It is same as Log,
+// except that values extracted from provided context.Context by registered
+// extractors are added to record. Explicitly provided pairs have precedence
+// over extracted values.
+func (l *Logger) LogContext(ctx context.Context, calldepth int, level Level, message string, pairs ...interface{}) {
+	// if level is not sufficient, do not proceed to avoid unneeded allocations
+	if !l.IsEnabledFor(level) {
+		return
+	}
+	var data = make(Ctx, len(l.bound)+len(pairs)/2)
+	for k, v := range l.bound {
+		data[k] = v
+	}
+	extractContext(ctx, data)
+	addPairs(data, pairs)
+
+	r := Record{
+		Time:    time.Now().UTC(),
+		Level:   level,
+		Message: message,
+		Context: data,
+		Logger:  l,
+	}
+	l.log(calldepth+1, r)
+}
+
+// DebugContext logs message in DEBUG level with values extracted from
+// provided context.Context. Additional parameters have same semantics as
+// in Log method.
+func (l *Logger) DebugContext(ctx context.Context, message string, pairs ...interface{}) {
+	l.LogContext(ctx, 2, DEBUG, message, pairs...)
+}
+
+// InfoContext logs message in INFO level with values extracted from
+// provided context.Context. Additional parameters have same semantics as
+// in Log method.
+func (l *Logger) InfoContext(ctx context.Context, message string, pairs ...interface{}) {
+	l.LogContext(ctx, 2, INFO, message, pairs...)
+}
+
+// WarningContext logs message in WARNING level with values extracted from
+// provided context.Context. Additional parameters have same semantics as
+// in Log method.
+func (l *Logger) WarningContext(ctx context.Context, message string, pairs ...interface{}) {
+	l.LogContext(ctx, 2, WARNING, message, pairs...)
+}
+
+// ErrorContext logs message in ERROR level with values extracted from
+// provided context.Context. Additional parameters have same semantics as
+// in Log method.
+func (l *Logger) ErrorContext(ctx context.Context, message string, pairs ...interface{}) {
+	l.LogContext(ctx, 2, ERROR, message, pairs...)
+}
+
+// CriticalContext logs message in CRITICAL level with values extracted from
+// provided context.Context. Additional parameters have same semantics as
+// in Log method.
+func (l *Logger) CriticalContext(ctx context.Context, message string, pairs ...interface{}) {
+	l.LogContext(ctx, 2, CRITICAL, message, pairs...)
+}
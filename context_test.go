@@ -0,0 +1,72 @@
+package ligno
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	if FromContext(context.Background()) != rootLogger {
+		t.Error("Expected root logger for context without logger.")
+	}
+	l := GetLogger("context." + randString())
+	ctx := NewContext(context.Background(), l)
+	if FromContext(ctx) != l {
+		t.Error("Expected logger stored in context.")
+	}
+}
+
+func TestContextExtractor(t *testing.T) {
+	t.Cleanup(RegisterContextExtractor(ContextKeyExtractor(requestIDKey{}, "request_id")))
+	var mu sync.Mutex
+	var records []Record
+	l := GetLoggerOptions("context."+randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+			return nil
+		}),
+		PreventPropagation: true,
+	})
+	ctx := NewContext(context.WithValue(context.Background(), requestIDKey{}, "abc"), l.With("bound", 1))
+	InfoContext(ctx, "extracted", "key", "value")
+	FromContext(ctx).WarningContext(ctx, "overridden", "request_id", "explicit")
+	l.InfoContext(context.Background(), "without request")
+	l.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	if c := records[0].Context; c["request_id"] != "abc" || c["key"] != "value" || c["bound"] != 1 {
+		t.Errorf("Unexpected context: %v", c)
+	}
+	if c := records[1].Context; c["request_id"] != "explicit" {
+		t.Errorf("Expected explicit pair to override extracted value, got %v", c)
+	}
+	if _, ok := records[2].Context["request_id"]; ok {
+		t.Errorf("Expected no request id, got %v", records[2].Context)
+	}
+}
+
+func TestRemoveContextExtractor(t *testing.T) {
+	remove := RegisterContextExtractor(ContextKeyExtractor(requestIDKey{}, "removed_id"))
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	extracted := Ctx{}
+	extractContext(ctx, extracted)
+	if extracted["removed_id"] != "abc" {
+		t.Fatalf("Expected registered extractor to be used, got %v", extracted)
+	}
+	remove()
+	remove()
+	extracted = Ctx{}
+	extractContext(ctx, extracted)
+	if _, ok := extracted["removed_id"]; ok {
+		t.Errorf("Expected removed extractor not to be used, got %v", extracted)
+	}
+}
@@ -1,8 +1,8 @@
 package ligno
 
 import (
+	"context"
 	"fmt"
-	"os"
 )
 
 // Log creates record and queues it for processing.
@@ -23,6 +23,37 @@ func LogCtx(level Level, message string, ctx Ctx) {
 	rootLogger.LogCtx(2, level, message, ctx)
 }
 
+// LogF creates record with provided fields and queues it for processing
+// with root logger. See Logger.LogF for details.
+func LogF(level Level, message string, fields ...Field) {
+	rootLogger.LogF(2, level, message, fields...)
+}
+
+// DebugF logs message in DEBUG level with provided fields.
+func DebugF(message string, fields ...Field) {
+	rootLogger.LogF(2, DEBUG, message, fields...)
+}
+
+// InfoF logs message in INFO level with provided fields.
+func InfoF(message string, fields ...Field) {
+	rootLogger.LogF(2, INFO, message, fields...)
+}
+
+// WarningF logs message in WARNING level with provided fields.
+func WarningF(message string, fields ...Field) {
+	rootLogger.LogF(2, WARNING, message, fields...)
+}
+
+// ErrorF logs message in ERROR level with provided fields.
+func ErrorF(message string, fields ...Field) {
+	rootLogger.LogF(2, ERROR, message, fields...)
+}
+
+// CriticalF logs message in CRITICAL level with provided fields.
+func CriticalF(message string, fields ...Field) {
+	rootLogger.LogF(2, CRITICAL, message, fields...)
+}
+
 // Debug creates log record and queues it for processing with DEBUG level.
 // Additional parameters have same semantics as in Log method.
 func Debug(event string, pairs ...interface{}) {
@@ -78,6 +109,43 @@ func CriticalCtx(message string, ctx Ctx) {
 	rootLogger.LogCtx(2, CRITICAL, message, ctx)
 }
 
+// LogContext logs message in specified level to logger stored in provided
+// context.Context (or root logger if there is none) with values extracted
+// from context by registered extractors.
+func LogContext(ctx context.Context, level Level, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, level, message, pairs...)
+}
+
+// DebugContext logs message in DEBUG level to logger stored in provided
+// context.Context. See LogContext for details.
+func DebugContext(ctx context.Context, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, DEBUG, message, pairs...)
+}
+
+// InfoContext logs message in INFO level to logger stored in provided
+// context.Context. See LogContext for details.
+func InfoContext(ctx context.Context, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, INFO, message, pairs...)
+}
+
+// WarningContext logs message in WARNING level to logger stored in provided
+// context.Context. See LogContext for details.
+func WarningContext(ctx context.Context, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, WARNING, message, pairs...)
+}
+
+// ErrorContext logs message in ERROR level to logger stored in provided
+// context.Context. See LogContext for details.
+func ErrorContext(ctx context.Context, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, ERROR, message, pairs...)
+}
+
+// CriticalContext logs message in CRITICAL level to logger stored in provided
+// context.Context. See LogContext for details.
+func CriticalContext(ctx context.Context, message string, pairs ...interface{}) {
+	FromContext(ctx).LogContext(ctx, 2, CRITICAL, message, pairs...)
+}
+
 // Printf formats message according to stdlib rules and logs it in INFO level.
 func Printf(format string, v ...interface{}) {
 	rootLogger.Log(2, INFO, fmt.Sprintf(format, v...))
@@ -94,47 +162,53 @@ func Println(v ...interface{}) {
 }
 
 // Fatal formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func Fatal(v ...interface{}) {
 	rootLogger.Log(2, CRITICAL, fmt.Sprint(v...))
-	os.Exit(1)
+	rootLogger.exit(1)
 }
 
 // Fatalf formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func Fatalf(format string, v ...interface{}) {
 	rootLogger.Log(2, CRITICAL, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	rootLogger.exit(1)
 }
 
 // Fatalln formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func Fatalln(v ...interface{}) {
 	rootLogger.Log(2, CRITICAL, fmt.Sprintln(v...))
-	os.Exit(1)
+	rootLogger.exit(1)
 }
 
 // Panic formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func Panic(v ...interface{}) {
 	s := fmt.Sprint(v...)
 	rootLogger.Log(2, CRITICAL, s)
+	rootLogger.waitPropagated()
 	panic(s)
 }
 
 // Panicf formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func Panicf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
 	rootLogger.Log(2, CRITICAL, s)
+	rootLogger.waitPropagated()
 	panic(s)
 }
 
 // Panicln formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func Panicln(v ...interface{}) {
 	s := fmt.Sprintln(v...)
 	rootLogger.Log(2, CRITICAL, s)
+	rootLogger.waitPropagated()
 	panic(s)
 }
 
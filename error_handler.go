@@ -0,0 +1,64 @@
+package ligno
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// ErrorHandler is function that is called when handler fails to process
+// log record. It receives record that failed to be processed, handler that
+// failed and error returned by handler.
+type ErrorHandler func(record Record, handler Handler, err error)
+
+// StderrErrorHandler is ErrorHandler that writes information about failed
+// handler to standard error output. It is used if no other error handler
+// is configured.
+func StderrErrorHandler(record Record, handler Handler, err error) {
+	loggerName := ""
+	if record.Logger != nil {
+		loggerName = record.Logger.FullName()
+	}
+	fmt.Fprintf(os.Stderr, "ligno: handler %T failed to process record %q of logger %q: %v\n",
+		handler, record.Message, loggerName, err)
+}
+
+// defaultErrorHandler holds error handler used by loggers that do not have
+// error handler set in options.
+var defaultErrorHandler atomic.Value
+
+func init() {
+	defaultErrorHandler.Store(ErrorHandler(StderrErrorHandler))
+}
+
+// SetErrorHandler sets error handler that will be used by all loggers that
+// do not have error handler set in their options. Setting nil restores
+// default StderrErrorHandler.
+func SetErrorHandler(errorHandler ErrorHandler) {
+	if errorHandler == nil {
+		errorHandler = StderrErrorHandler
+	}
+	defaultErrorHandler.Store(errorHandler)
+}
+
+// getErrorHandler returns error handler that should be used by logger.
+// Default error handler is returned for nil logger (e.g. for records that
+// were not created by logger).
+func (l *Logger) getErrorHandler() ErrorHandler {
+	if l != nil && l.errorHandler != nil {
+		return l.errorHandler
+	}
+	return defaultErrorHandler.Load().(ErrorHandler)
+}
+
+// callHandler passes record to logger's handler. Panics in handler are
+// recovered and returned as errors, so that failing handler can not crash
+// application.
+func (l *Logger) callHandler(record Record) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return l.handler.Handle(record)
+}
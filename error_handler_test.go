@@ -0,0 +1,56 @@
+package ligno
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestErrorHandlerCalled(t *testing.T) {
+	var mu sync.Mutex
+	var failed []error
+	handlerErr := errors.New("handler failed")
+	l := GetLoggerOptions("errors."+randString(), LoggerOptions{
+		Handler: CombiningHandler(
+			HandlerFunc(func(Record) error { return handlerErr }),
+			FileHandler(filepath.Join(t.TempDir(), "missing", "app.log"), SimpleFormat()),
+		),
+		PreventPropagation: true,
+		ErrorHandler: func(record Record, handler Handler, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, err)
+		},
+	})
+	l.Info("message")
+	l.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 1 {
+		t.Fatalf("Expected one failed record, got %d", len(failed))
+	}
+	if !errors.Is(failed[0], handlerErr) {
+		t.Errorf("Expected handler error to be reported, got %v", failed[0])
+	}
+	if !strings.Contains(failed[0].Error(), "app.log") {
+		t.Errorf("Expected file handler error to be reported, got %v", failed[0])
+	}
+}
+
+func TestErrorHandlerRecoversPanic(t *testing.T) {
+	var reported error
+	l := GetLoggerOptions("errors."+randString(), LoggerOptions{
+		Handler:            HandlerFunc(func(Record) error { panic("boom") }),
+		PreventPropagation: true,
+		ErrorHandler: func(record Record, handler Handler, err error) {
+			reported = err
+		},
+	})
+	l.Info("message")
+	l.Wait()
+	if reported == nil {
+		t.Fatal("Expected panic in handler to be reported as error.")
+	}
+}
@@ -0,0 +1,105 @@
+package ligno
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorFielder is interface that errors can implement to provide additional
+// fields that are rendered together with error message.
+type ErrorFielder interface {
+	LogFields() Ctx
+}
+
+// ErrorDetails holds structured information about error, including errors
+// it wraps (obtained with Unwrap() error or Unwrap() []error methods, as
+// used by errors.Unwrap and errors.Join).
+type ErrorDetails struct {
+	Message string         `json:"message"`
+	Type    string         `json:"type"`
+	Fields  Ctx            `json:"fields,omitempty"`
+	Causes  []ErrorDetails `json:"causes,omitempty"`
+}
+
+// maxErrorDepth is max depth of wrapped errors that are described.
+const maxErrorDepth = 16
+
+// DescribeError returns structured information about provided error.
+func DescribeError(err error) ErrorDetails {
+	return describeError(err, 0)
+}
+
+// describeError returns structured information about error that is wrapped
+// in provided depth.
+func describeError(err error, depth int) ErrorDetails {
+	details := ErrorDetails{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	if fielder, ok := err.(ErrorFielder); ok {
+		details.Fields = fielder.LogFields()
+	}
+	if depth >= maxErrorDepth {
+		return details
+	}
+	var causes []error
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = e.Unwrap()
+	case interface{ Unwrap() error }:
+		causes = []error{e.Unwrap()}
+	}
+	for _, cause := range causes {
+		if cause != nil {
+			details.Causes = append(details.Causes, describeError(cause, depth+1))
+		}
+	}
+	return details
+}
+
+// String returns human readable summary of error in single line, with
+// message, type and fields of error followed by its causes.
+func (d ErrorDetails) String() string {
+	var b strings.Builder
+	d.writeSummary(&b)
+	return b.String()
+}
+
+// writeSummary writes human readable summary of error to builder.
+func (d ErrorDetails) writeSummary(b *strings.Builder) {
+	b.WriteString(d.Message)
+	b.WriteString(" (")
+	b.WriteString(d.Type)
+	b.WriteRune(')')
+	if len(d.Fields) > 0 {
+		keys := make([]string, 0, len(d.Fields))
+		for k := range d.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString(" {")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteRune(' ')
+			}
+			fmt.Fprintf(b, "%s=%+v", k, d.Fields[k])
+		}
+		b.WriteRune('}')
+	}
+	switch len(d.Causes) {
+	case 0:
+	case 1:
+		b.WriteString(" <- ")
+		d.Causes[0].writeSummary(b)
+	default:
+		b.WriteString(" <- [")
+		for i, cause := range d.Causes {
+			if i > 0 {
+				b.WriteString("; ")
+			}
+			cause.writeSummary(b)
+		}
+		b.WriteRune(']')
+	}
+}
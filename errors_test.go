@@ -0,0 +1,65 @@
+package ligno
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func (e *codeError) LogFields() Ctx {
+	return Ctx{"code": e.code}
+}
+
+func TestDescribeError(t *testing.T) {
+	err := fmt.Errorf("request failed: %w", errors.Join(&codeError{code: 42}, errors.New("timeout")))
+	details := DescribeError(err)
+	if details.Type != "*fmt.wrapError" || len(details.Causes) != 1 {
+		t.Fatalf("Unexpected details: %+v", details)
+	}
+	joined := details.Causes[0]
+	if len(joined.Causes) != 2 {
+		t.Fatalf("Expected joined errors as causes, got %+v", joined)
+	}
+	if joined.Causes[0].Type != "*ligno.codeError" || joined.Causes[0].Fields["code"] != 42 {
+		t.Errorf("Expected fields of error, got %+v", joined.Causes[0])
+	}
+	summary := details.String()
+	for _, part := range []string{"request failed", "(*fmt.wrapError)", "{code=42}", "timeout (*errors.errorString)"} {
+		if !strings.Contains(summary, part) {
+			t.Errorf("Expected %q in summary %q", part, summary)
+		}
+	}
+}
+
+func TestJSONFormatError(t *testing.T) {
+	ctx := Ctx{"err": fmt.Errorf("wrapped: %w", &codeError{code: 7}), "user": "john"}
+	formatted := JSONFormat(false).Format(Record{Level: ERROR, Message: "failed", Context: ctx})
+	var out struct {
+		Context struct {
+			Err  ErrorDetails `json:"err"`
+			User string       `json:"user"`
+		} `json:"context"`
+	}
+	if err := json.Unmarshal(formatted, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Context.Err.Message != "wrapped: code 7" || len(out.Context.Err.Causes) != 1 {
+		t.Errorf("Unexpected error in JSON output: %s", formatted)
+	}
+	if out.Context.Err.Causes[0].Fields["code"] != "7" {
+		t.Errorf("Expected fields of wrapped error, got %s", formatted)
+	}
+	if _, ok := ctx["err"].(error); !ok {
+		t.Error("Expected original context not to be modified.")
+	}
+}
@@ -0,0 +1,84 @@
+package ligno
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultExitTimeout is max time Fatal and Panic functions wait for record
+// to be processed if timeout is not set with SetExitTimeout.
+const defaultExitTimeout = 5 * time.Second
+
+// exitSettings holds configuration of Fatal and Panic functions.
+var exitSettings = struct {
+	sync.RWMutex
+	exit    func(code int)
+	timeout time.Duration
+}{
+	exit:    os.Exit,
+	timeout: defaultExitTimeout,
+}
+
+// SetExitFunc sets function that is called by Fatal functions instead of
+// os.Exit, for example to intercept exit in tests. If function returns,
+// Fatal functions return as well. Setting nil restores os.Exit.
+func SetExitFunc(exit func(code int)) {
+	if exit == nil {
+		exit = os.Exit
+	}
+	exitSettings.Lock()
+	defer exitSettings.Unlock()
+	exitSettings.exit = exit
+}
+
+// SetExitTimeout sets max time Fatal and Panic functions wait for logged
+// record to be processed by logger and all loggers it is propagated to,
+// before exiting or panicking. Zero or negative value disables waiting.
+func SetExitTimeout(timeout time.Duration) {
+	exitSettings.Lock()
+	defer exitSettings.Unlock()
+	exitSettings.timeout = timeout
+}
+
+// exit waits for queued records to be processed and calls exit function.
+func (l *Logger) exit(code int) {
+	l.waitPropagated()
+	exitSettings.RLock()
+	exit := exitSettings.exit
+	exitSettings.RUnlock()
+	exit(code)
+}
+
+// waitPropagated blocks until all records queued to this logger and all
+// its ancestors that records are propagated to are processed and their
+// handlers are flushed, or until exit timeout expires. Ancestors are waited
+// for in order, since records are propagated to parent before child
+// handles them.
+func (l *Logger) waitPropagated() bool {
+	exitSettings.RLock()
+	timeout := exitSettings.timeout
+	exitSettings.RUnlock()
+	if timeout <= 0 {
+		return false
+	}
+	done := make(chan struct{})
+	go func() {
+		for current := l; current != nil; current = current.relationship.parent {
+			current.drain()
+			current.flush()
+			if current.PreventPropagation() {
+				break
+			}
+		}
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package ligno
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFatalWaitsForPropagatedRecord(t *testing.T) {
+	var mu sync.Mutex
+	var handled []string
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			handled = append(handled, record.Message)
+			mu.Unlock()
+			return nil
+		}),
+		PreventPropagation: true,
+	})
+	defer parent.StopAndWait()
+	child := parent.SubLogger("child")
+	defer child.StopAndWait()
+
+	exitCode := -1
+	SetExitFunc(func(code int) {
+		exitCode = code
+		mu.Lock()
+		defer mu.Unlock()
+		if len(handled) != 1 || handled[0] != "dying" {
+			t.Errorf("Expected record to be handled before exit, got %v", handled)
+		}
+	})
+	defer SetExitFunc(nil)
+	child.Fatalf("dying")
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestPanicWaitsForRecordWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			<-release
+			return nil
+		}),
+		PreventPropagation: true,
+	})
+	defer l.StopAndWait()
+	defer close(release)
+	SetExitTimeout(20 * time.Millisecond)
+	defer SetExitTimeout(defaultExitTimeout)
+
+	start := time.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != "stuck" {
+				t.Errorf("Expected panic with message, got %v", r)
+			}
+		}()
+		l.Panic("stuck")
+	}()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected waiting to be limited by timeout, took %v", elapsed)
+	}
+}
@@ -0,0 +1,298 @@
+package ligno
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FieldType identifies type of value held by Field.
+type FieldType uint8
+
+// Types of field values.
+const (
+	// UnknownType is type of zero Field.
+	UnknownType FieldType = iota
+	// StringType is type of fields created with String.
+	StringType
+	// IntType is type of fields created with Int and Int64.
+	IntType
+	// UintType is type of fields created with Uint and Uint64.
+	UintType
+	// FloatType is type of fields created with Float64.
+	FloatType
+	// BoolType is type of fields created with Bool.
+	BoolType
+	// DurationType is type of fields created with Dur.
+	DurationType
+	// TimeType is type of fields created with Time.
+	TimeType
+	// ErrorType is type of fields created with Err.
+	ErrorType
+	// ObjectType is type of fields created with Object.
+	ObjectType
+)
+
+// Field is typed key-value pair that can be added to record without
+// allocating context map and boxing value. Fields are created with
+// functions String, Int, Dur, Err, etc. and logged with LogF and
+// InfoF-like methods of Logger. Value is stored in one of Integer, String
+// or Interface members, depending on Type.
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// String creates field with string value.
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int creates field with int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Int64 creates field with int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Uint creates field with uint value.
+func Uint(key string, value uint) Field {
+	return Field{Key: key, Type: UintType, Integer: int64(value)}
+}
+
+// Uint64 creates field with uint64 value.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: UintType, Integer: int64(value)}
+}
+
+// Float64 creates field with float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates field with bool value.
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Dur creates field with duration value.
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates field with time value.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Interface: value.Location()}
+}
+
+// Err creates field with key "err" holding provided error, same key that
+// is used when error is logged as last unpaired value with Log.
+func Err(err error) Field {
+	return Field{Key: "err", Type: ErrorType, Interface: err}
+}
+
+// Object creates field with arbitrary value. Such value is boxed and
+// formatters may need reflection to encode it, so typed constructors should
+// be preferred when possible.
+func Object(key string, value interface{}) Field {
+	return Field{Key: key, Type: ObjectType, Interface: value}
+}
+
+// Value returns value of field as interface{}.
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case IntType:
+		return f.Integer
+	case UintType:
+		return uint64(f.Integer)
+	case FloatType:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.time()
+	default:
+		return f.Interface
+	}
+}
+
+// time returns value of time field.
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Interface.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// AppendText appends text representation of field value to dst and
+// returns extended buffer. Values of all types except objects are encoded
+// without reflection.
+func (f Field) AppendText(dst []byte) []byte {
+	switch f.Type {
+	case StringType:
+		return append(dst, f.String...)
+	case IntType:
+		return strconv.AppendInt(dst, f.Integer, 10)
+	case UintType:
+		return strconv.AppendUint(dst, uint64(f.Integer), 10)
+	case FloatType:
+		return strconv.AppendFloat(dst, math.Float64frombits(uint64(f.Integer)), 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(dst, f.Integer == 1)
+	case DurationType:
+		return append(dst, time.Duration(f.Integer).String()...)
+	case TimeType:
+		return f.time().AppendFormat(dst, time.RFC3339Nano)
+	case ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return append(dst, err.Error()...)
+		}
+		return append(dst, "nil"...)
+	default:
+		return append(dst, logfmtValue(f.Interface)...)
+	}
+}
+
+// fieldBuffer is pooled storage for fields of single record. Since record
+// is processed by logger that created it and all loggers it is propagated
+// to, buffer is returned to pool only after all of them are done with it.
+type fieldBuffer struct {
+	fields []Field
+	refs   int32
+}
+
+// fieldBufferPool holds field buffers for reuse.
+var fieldBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &fieldBuffer{fields: make([]Field, 0, 8)}
+	},
+}
+
+// maxPooledFields is max capacity of field buffer that is returned to pool,
+// so that occasional record with lots of fields does not keep memory.
+const maxPooledFields = 64
+
+// newFieldBuffer returns buffer from pool holding copy of provided fields,
+// with one reference held by caller.
+func newFieldBuffer(fields []Field) *fieldBuffer {
+	fb := fieldBufferPool.Get().(*fieldBuffer)
+	fb.fields = append(fb.fields[:0], fields...)
+	fb.refs = 1
+	return fb
+}
+
+// retainFields adds reference to field buffer of record, if it has one.
+func (r *Record) retainFields() {
+	if r.fieldBuf != nil {
+		atomic.AddInt32(&r.fieldBuf.refs, 1)
+	}
+}
+
+// releaseFields removes reference to field buffer of record, if it has one,
+// returning buffer to pool once there are no more references.
+func (r *Record) releaseFields() {
+	fb := r.fieldBuf
+	if fb == nil || atomic.AddInt32(&fb.refs, -1) != 0 {
+		return
+	}
+	if cap(fb.fields) > maxPooledFields {
+		return
+	}
+	for i := range fb.fields {
+		fb.fields[i] = Field{}
+	}
+	fb.fields = fb.fields[:0]
+	fieldBufferPool.Put(fb)
+}
+
+// Detach returns copy of record whose fields are not pooled, so it can be
+// kept after handler returns. Fields of records passed to handlers are
+// reused once Handle returns, so handlers that keep records (instead of
+// formatting them right away) must keep detached copies.
+func (r Record) Detach() Record {
+	if r.fieldBuf != nil {
+		r.Fields = append([]Field(nil), r.Fields...)
+		r.fieldBuf = nil
+	}
+	return r
+}
+
+// resolveFields replaces values of object fields that implement LogValuer
+// with values they resolve to.
+func resolveFields(fields []Field) {
+	for i := range fields {
+		if fields[i].Type != ObjectType {
+			continue
+		}
+		switch fields[i].Interface.(type) {
+		case LogValuer, slog.LogValuer:
+			fields[i].Interface = resolveValue(fields[i].Interface)
+		}
+	}
+}
+
+// LogF creates record with provided fields and queues it for processing.
+// Unlike Log, it does not allocate context map for fields. Fields are
+// copied, so provided slice can be reused once LogF returns.
+func (l *Logger) LogF(calldepth int, level Level, message string, fields ...Field) {
+	// if level is not sufficient, do not proceed to avoid unneeded allocations
+	if !l.IsEnabledFor(level) {
+		return
+	}
+	r := Record{
+		Time:    time.Now().UTC(),
+		Level:   level,
+		Message: message,
+		Context: l.bound,
+		Logger:  l,
+	}
+	if len(fields) > 0 {
+		r.fieldBuf = newFieldBuffer(fields)
+		r.Fields = r.fieldBuf.fields
+	}
+	l.log(calldepth+1, r)
+	r.releaseFields()
+}
+
+// DebugF logs message in DEBUG level with provided fields.
+func (l *Logger) DebugF(message string, fields ...Field) {
+	l.LogF(2, DEBUG, message, fields...)
+}
+
+// InfoF logs message in INFO level with provided fields.
+func (l *Logger) InfoF(message string, fields ...Field) {
+	l.LogF(2, INFO, message, fields...)
+}
+
+// WarningF logs message in WARNING level with provided fields.
+func (l *Logger) WarningF(message string, fields ...Field) {
+	l.LogF(2, WARNING, message, fields...)
+}
+
+// ErrorF logs message in ERROR level with provided fields.
+func (l *Logger) ErrorF(message string, fields ...Field) {
+	l.LogF(2, ERROR, message, fields...)
+}
+
+// CriticalF logs message in CRITICAL level with provided fields.
+func (l *Logger) CriticalF(message string, fields ...Field) {
+	l.LogF(2, CRITICAL, message, fields...)
+}
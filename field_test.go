@@ -0,0 +1,164 @@
+package ligno
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldAppendText(t *testing.T) {
+	now := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		field    Field
+		expected string
+	}{
+		{String("k", "v"), "v"},
+		{Int("k", -5), "-5"},
+		{Uint64("k", 7), "7"},
+		{Float64("k", 1.5), "1.5"},
+		{Bool("k", true), "true"},
+		{Dur("k", time.Second), "1s"},
+		{Time("k", now), "2016-01-02T03:04:05Z"},
+		{Err(errors.New("boom")), "boom"},
+		{Object("k", []int{1, 2}), "[1,2]"},
+	} {
+		if got := string(tc.field.AppendText(nil)); got != tc.expected {
+			t.Errorf("Expected %q for field type %d, got %q", tc.expected, tc.field.Type, got)
+		}
+	}
+	if got := Time("k", now).Value(); got != now {
+		t.Errorf("Expected time value %v, got %v", now, got)
+	}
+}
+
+func TestLogFPropagation(t *testing.T) {
+	parentHandler := MemoryHandler(LogfmtFormat(LogfmtOptions{TimeKey: "-"}))
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            parentHandler,
+		PreventPropagation: true,
+	})
+	defer parent.StopAndWait()
+	childHandler := MemoryHandler(ThemedTerminalFormat(NoColorTheme))
+	child := parent.SubLoggerOptions("child", LoggerOptions{
+		Handler: childHandler,
+		Context: Ctx{"component": "c"},
+	})
+	defer child.StopAndWait()
+
+	for i := 0; i < 100; i++ {
+		child.InfoF("event", String("user", "bob smith"), Int("i", i))
+	}
+	parent.Wait()
+
+	childMessages := childHandler.Messages()
+	parentMessages := parentHandler.Messages()
+	if len(childMessages) != 100 || len(parentMessages) != 100 {
+		t.Fatalf("Expected 100 messages, got %d and %d", len(childMessages), len(parentMessages))
+	}
+	if expected := `[component="c" user="bob smith" i="99"]`; !strings.Contains(childMessages[99], expected) {
+		t.Errorf("Expected %s in %q", expected, childMessages[99])
+	}
+	if expected := `component=c user="bob smith" i=99`; !strings.Contains(parentMessages[99], expected) {
+		t.Errorf("Expected %s in %q", expected, parentMessages[99])
+	}
+}
+
+func TestLogFAllocations(t *testing.T) {
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            NullHandler(),
+		PreventPropagation: true,
+	})
+	defer l.StopAndWait()
+	allocs := testing.AllocsPerRun(100, func() {
+		l.InfoF("event", String("user", "bob"), Int("attempt", 3), Dur("elapsed", time.Second))
+		l.Wait()
+	})
+	if allocs > 2 {
+		t.Errorf("Expected no more then 2 allocations per record, got %v", allocs)
+	}
+}
+
+func BenchmarkLogPairs(b *testing.B) {
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            NullHandler(),
+		PreventPropagation: true,
+		BufferSize:         b.N + 1,
+	})
+	defer l.StopAndWait()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("event", "user", "bob", "attempt", 3, "elapsed", time.Second)
+	}
+	l.Wait()
+}
+
+func BenchmarkLogFields(b *testing.B) {
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            NullHandler(),
+		PreventPropagation: true,
+		BufferSize:         b.N + 1,
+	})
+	defer l.StopAndWait()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfoF("event", String("user", "bob"), Int("attempt", 3), Dur("elapsed", time.Second))
+	}
+	l.Wait()
+}
+
+func TestJSONFormatFields(t *testing.T) {
+	record := Record{
+		Message: "event",
+		Context: Ctx{"user": "from context"},
+		Fields: []Field{
+			String("user", "from field"),
+			Int("attempt", 3),
+			Bool("ok", true),
+			String("quoted", `a "b"`),
+			Err(errors.New("boom")),
+		},
+	}
+	var marshaled struct {
+		Context map[string]interface{} `json:"context"`
+	}
+	if err := json.Unmarshal(JSONFormat(false).Format(record), &marshaled); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"user":    "from context",
+		"attempt": "3",
+		"ok":      "true",
+		"quoted":  `a "b"`,
+	}
+	for k, v := range expected {
+		if marshaled.Context[k] != v {
+			t.Errorf("Expected %q for key %s, got %#v", v, k, marshaled.Context[k])
+		}
+	}
+	if err, ok := marshaled.Context["err"].(map[string]interface{}); !ok || err["message"] != "boom" {
+		t.Errorf("Expected error details, got %#v", marshaled.Context["err"])
+	}
+
+	formatter := JSONFormat(false)
+	record = Record{Message: "event", Fields: []Field{String("user", "bob"), Int("attempt", 3), Dur("elapsed", time.Second)}}
+	allocs := testing.AllocsPerRun(100, func() {
+		formatter.Format(record)
+	})
+	if allocs > 2 {
+		t.Errorf("Expected no more then 2 allocations per formatted record, got %v", allocs)
+	}
+}
+
+func TestRecordDetach(t *testing.T) {
+	r := Record{Message: "detached"}
+	r.fieldBuf = newFieldBuffer([]Field{String("user", "john")})
+	r.Fields = r.fieldBuf.fields
+	detached := r.Detach()
+	// released fields are cleared before buffer is returned to pool
+	r.releaseFields()
+	if detached.fieldBuf != nil || len(detached.Fields) != 1 || detached.Fields[0].String != "john" {
+		t.Errorf("Expected detached record to keep its fields, got %+v", detached.Fields)
+	}
+}
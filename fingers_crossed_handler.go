@@ -0,0 +1,196 @@
+package ligno
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultFingersCrossedMaxKeys is number of buffers kept by fingers crossed
+// handler if not specified otherwise.
+const defaultFingersCrossedMaxKeys = 1000
+
+// FingersCrossedOptions holds additional configuration for fingers crossed
+// handler.
+type FingersCrossedOptions struct {
+	// KeyField is name of context field whose value is used to keep
+	// separate buffers, so that triggering for one key (e.g. request_id)
+	// does not flush records for other keys. Records without this field
+	// share single buffer. Empty value means that single buffer is used for
+	// all records.
+	KeyField string
+	// Reset is predicate that returns handler to buffering mode once it is
+	// satisfied by record received after trigger. Record that satisfied
+	// predicate is still passed to handler.
+	Reset Predicate
+	// ResetAfter is duration after last triggering record after which
+	// handler returns to buffering mode. Zero means that handler does not
+	// reset based on time.
+	ResetAfter time.Duration
+	// MaxKeys is maximal number of buffers kept. When exceeded, buffer that
+	// was least recently used is discarded. Zero means default of 1000.
+	MaxKeys int
+}
+
+// fingersCrossedBuffer holds state for records with same key.
+type fingersCrossedBuffer struct {
+	key         string
+	records     []Record
+	start       int
+	size        int
+	triggered   bool
+	lastTrigger time.Time
+}
+
+// push adds record to ring buffer, overwriting oldest record when full.
+func (b *fingersCrossedBuffer) push(record Record) {
+	if len(b.records) == 0 {
+		return
+	}
+	if b.size < len(b.records) {
+		b.records[(b.start+b.size)%len(b.records)] = record
+		b.size++
+		return
+	}
+	b.records[b.start] = record
+	b.start = (b.start + 1) % len(b.records)
+}
+
+// flush returns all buffered records in order they were received and
+// empties buffer.
+func (b *fingersCrossedBuffer) flush() []Record {
+	records := make([]Record, 0, b.size+1)
+	for i := 0; i < b.size; i++ {
+		idx := (b.start + i) % len(b.records)
+		records = append(records, b.records[idx])
+		b.records[idx] = Record{}
+	}
+	b.start, b.size = 0, 0
+	return records
+}
+
+// fingersCrossedHandler buffers records until record with trigger level
+// is received.
+type fingersCrossedHandler struct {
+	trigger    Level
+	bufferSize int
+	handler    Handler
+	options    FingersCrossedOptions
+	mu         sync.Mutex
+	// buffers holds *fingersCrossedBuffer ordered from most recently used.
+	buffers *list.List
+	byKey   map[string]*list.Element
+}
+
+// FingersCrossedHandler creates handler that keeps last bufferSize records
+// in memory and passes all of them to provided handler once record with
+// level trigger or above is received. After that all records are passed
+// through to handler. Records that are still buffered when handler is
+// closed are discarded, since trigger level was not reached for them.
+func FingersCrossedHandler(trigger Level, bufferSize int, handler Handler) Handler {
+	return FingersCrossedHandlerOptions(trigger, bufferSize, handler, FingersCrossedOptions{})
+}
+
+// FingersCrossedHandlerOptions creates fingers crossed handler (see
+// FingersCrossedHandler) with additional options for keying buffers and
+// resetting to buffering mode.
+func FingersCrossedHandlerOptions(trigger Level, bufferSize int, handler Handler, options FingersCrossedOptions) Handler {
+	if options.MaxKeys <= 0 {
+		options.MaxKeys = defaultFingersCrossedMaxKeys
+	}
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	return &fingersCrossedHandler{
+		trigger:    trigger,
+		bufferSize: bufferSize,
+		handler:    handler,
+		options:    options,
+		buffers:    list.New(),
+		byKey:      make(map[string]*list.Element),
+	}
+}
+
+// Handle buffers record or passes it to handler, depending on state of
+// buffer record belongs to.
+func (fh *fingersCrossedHandler) Handle(record Record) error {
+	fh.mu.Lock()
+	defer fh.mu.Unlock()
+	buffer := fh.buffer(record)
+
+	if buffer.triggered && fh.options.ResetAfter > 0 &&
+		record.Time.Sub(buffer.lastTrigger) >= fh.options.ResetAfter {
+		buffer.triggered = false
+	}
+
+	if buffer.triggered {
+		if record.Level >= fh.trigger {
+			buffer.lastTrigger = record.Time
+		}
+		if fh.options.Reset != nil && fh.options.Reset(record) {
+			buffer.triggered = false
+		}
+		return fh.handler.Handle(record)
+	}
+
+	if record.Level < fh.trigger {
+		buffer.push(record.Detach())
+		return nil
+	}
+
+	buffer.triggered = true
+	buffer.lastTrigger = record.Time
+	var errs []error
+	for _, r := range append(buffer.flush(), record) {
+		if err := fh.handler.Handle(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close discards all buffered records and closes handler if it implements
+// HandlerCloser interface.
+func (fh *fingersCrossedHandler) Close() {
+	fh.mu.Lock()
+	fh.buffers.Init()
+	fh.byKey = make(map[string]*list.Element)
+	fh.mu.Unlock()
+	if handlerCloser, ok := fh.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// Flush flushes handler if it implements HandlerFlusher. Buffered records
+// are not passed to handler, since it is not triggered.
+func (fh *fingersCrossedHandler) Flush() error {
+	return flushHandler(fh.handler)
+}
+
+// buffer returns buffer that record belongs to, creating it if needed and
+// evicting least recently used buffer if there are too many of them.
+func (fh *fingersCrossedHandler) buffer(record Record) *fingersCrossedBuffer {
+	var key string
+	if fh.options.KeyField != "" {
+		if value, ok := record.Value(fh.options.KeyField); ok {
+			key = fmt.Sprint(value)
+		}
+	}
+	if elem, ok := fh.byKey[key]; ok {
+		fh.buffers.MoveToFront(elem)
+		return elem.Value.(*fingersCrossedBuffer)
+	}
+	buffer := &fingersCrossedBuffer{
+		key:     key,
+		records: make([]Record, fh.bufferSize),
+	}
+	fh.byKey[key] = fh.buffers.PushFront(buffer)
+	for fh.buffers.Len() > fh.options.MaxKeys {
+		oldest := fh.buffers.Back()
+		fh.buffers.Remove(oldest)
+		delete(fh.byKey, oldest.Value.(*fingersCrossedBuffer).key)
+	}
+	return buffer
+}
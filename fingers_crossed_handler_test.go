@@ -0,0 +1,68 @@
+package ligno
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFingersCrossedHandler(t *testing.T) {
+	var messages []string
+	handler := FingersCrossedHandler(ERROR, 2, HandlerFunc(func(record Record) error {
+		messages = append(messages, record.Message)
+		return nil
+	}))
+
+	handler.Handle(Record{Level: DEBUG, Message: "first"})
+	handler.Handle(Record{Level: DEBUG, Message: "second"})
+	handler.Handle(Record{Level: INFO, Message: "third"})
+	if len(messages) != 0 {
+		t.Fatalf("Expected records to be buffered, got %v", messages)
+	}
+
+	handler.Handle(Record{Level: ERROR, Message: "failure"})
+	handler.Handle(Record{Level: DEBUG, Message: "after"})
+	expected := []string{"second", "third", "failure", "after"}
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected messages %v, got %v", expected, messages)
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Errorf("Expected messages %v, got %v", expected, messages)
+			break
+		}
+	}
+}
+
+func TestFingersCrossedHandlerKeyAndReset(t *testing.T) {
+	var handled []Record
+	now := time.Now()
+	handler := FingersCrossedHandlerOptions(ERROR, 10, HandlerFunc(func(record Record) error {
+		handled = append(handled, record)
+		return nil
+	}), FingersCrossedOptions{
+		KeyField:   "request_id",
+		ResetAfter: time.Minute,
+		Reset: func(record Record) bool {
+			return record.Message == "done"
+		},
+	})
+
+	handler.Handle(Record{Time: now, Level: DEBUG, Message: "a1", Context: Ctx{"request_id": "a"}})
+	handler.Handle(Record{Time: now, Level: DEBUG, Message: "b1", Context: Ctx{"request_id": "b"}})
+	handler.Handle(Record{Time: now, Level: ERROR, Message: "a2", Context: Ctx{"request_id": "a"}})
+	if len(handled) != 2 || handled[0].Message != "a1" || handled[1].Message != "a2" {
+		t.Fatalf("Expected only records for request a, got %v", handled)
+	}
+
+	handler.Handle(Record{Time: now, Level: DEBUG, Message: "done", Context: Ctx{"request_id": "a"}})
+	handler.Handle(Record{Time: now, Level: DEBUG, Message: "a3", Context: Ctx{"request_id": "a"}})
+	if len(handled) != 3 {
+		t.Fatalf("Expected buffering after reset, got %d records", len(handled))
+	}
+
+	handler.Handle(Record{Time: now, Level: ERROR, Message: "b2", Context: Ctx{"request_id": "b"}})
+	handler.Handle(Record{Time: now.Add(2 * time.Minute), Level: DEBUG, Message: "b3", Context: Ctx{"request_id": "b"}})
+	if last := handled[len(handled)-1]; last.Message != "b2" {
+		t.Errorf("Expected buffering after reset timeout, last handled record is %v", last.Message)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	isatty "github.com/mattn/go-isatty"
 )
@@ -47,7 +49,9 @@ func SimpleFormat() Formatter {
 			buff.WriteRune(']')
 		}
 		buff.WriteRune('\n')
-		return buff.Bytes()
+		appendStack(buff, record.Stack)
+		// buffer is returned to pool and reused, so return copy of its content
+		return append([]byte(nil), buff.Bytes()...)
 	})
 }
 
@@ -85,35 +89,75 @@ func ThemedTerminalFormat(theme Theme) Formatter {
 		}
 		sort.Strings(keys)
 
-		if len(keys) > 0 {
+		pairs := len(keys) + len(record.Fields)
+		if pairs > 0 {
 			buff.WriteString(" [")
 		}
 		for i := 0; i < len(keys); i++ {
 			k := keys[i]
-			keyQuote := strings.IndexFunc(k, needsQuote) >= 0 || k == ""
-			if keyQuote {
-				buff.WriteRune('"')
+			appendTerminalKey(buff, k)
+			buff.WriteRune('"')
+			if err, ok := ctx[k].(error); ok {
+				buff.WriteString(DescribeError(err).String())
+			} else {
+				buff.WriteString(fmt.Sprintf("%+v", ctx[k]))
 			}
-			buff.WriteString(k)
-			if keyQuote {
-				buff.WriteRune('"')
+			buff.WriteRune('"')
+			if i < pairs-1 {
+				buff.WriteRune(' ')
 			}
-			buff.WriteRune('=')
+		}
+		for i, field := range record.Fields {
+			appendTerminalKey(buff, field.Key)
 			buff.WriteRune('"')
-			buff.WriteString(fmt.Sprintf("%+v", ctx[k]))
+			if err, ok := field.Interface.(error); ok && field.Type == ErrorType {
+				buff.WriteString(DescribeError(err).String())
+			} else {
+				buff.Write(field.AppendText(buff.AvailableBuffer()))
+			}
 			buff.WriteRune('"')
-			if i < len(keys)-1 {
+			if len(keys)+i < pairs-1 {
 				buff.WriteRune(' ')
 			}
 		}
-		if len(keys) > 0 {
+		if pairs > 0 {
 			buff.WriteRune(']')
 		}
 		buff.WriteRune('\n')
-		return buff.Bytes()
+		appendStack(buff, record.Stack)
+		// buffer is returned to pool and reused, so return copy of its content
+		return append([]byte(nil), buff.Bytes()...)
 	})
 }
 
+// appendTerminalKey writes context key followed by "=" to buffer, quoting
+// key if needed.
+func appendTerminalKey(buff *bytes.Buffer, k string) {
+	keyQuote := strings.IndexFunc(k, needsQuote) >= 0 || k == ""
+	if keyQuote {
+		buff.WriteRune('"')
+	}
+	buff.WriteString(k)
+	if keyQuote {
+		buff.WriteRune('"')
+	}
+	buff.WriteRune('=')
+}
+
+// appendStack writes stack trace to buffer in multiple lines, formatted
+// similar to stack traces printed by Go runtime.
+func appendStack(buff *bytes.Buffer, stack []StackFrame) {
+	for _, frame := range stack {
+		buff.WriteRune('\t')
+		buff.WriteString(frame.Function)
+		buff.WriteString("\n\t\t")
+		buff.WriteString(frame.File)
+		buff.WriteRune(':')
+		buff.WriteString(strconv.Itoa(frame.Line))
+		buff.WriteRune('\n')
+	}
+}
+
 // Needs quote determines if provided rune is such that word that contains this
 // rune needs to be quoted.
 func needsQuote(r rune) bool {
@@ -121,29 +165,199 @@ func needsQuote(r rune) bool {
 		!unicode.IsPrint(r)
 }
 
-// JSONFormat is simple formatter that only marshals log record to json.
+// JSONFormat is formatter that encodes log record as JSON object.
+// Errors in context are rendered as nested objects (see ErrorDetails).
+// Fields are rendered as part of context, after context values, and are
+// encoded directly into buffer, without reflection, except for object and
+// error fields. Context has precedence over fields with same key.
 func JSONFormat(pretty bool) Formatter {
 	return FormatterFunc(func(record Record) []byte {
-		// since errors are not JSON serializable, make sure that all errors
-		// are converted to strings
-		for k, v := range record.Context {
-			record.Context[k] = fmt.Sprintf("%+v", v)
+		buff := buffPool.Get()
+		defer buffPool.Put(buff)
+		if err := appendJSONRecord(buff, record); err != nil {
+			marshaled, _ := json.Marshal(map[string]string{
+				"JSONError": err.Error(),
+			})
+			return append(marshaled, '\n')
 		}
-
-		// serialize
-		var marshaled []byte
-		var err error
 		if pretty {
-			marshaled, err = json.MarshalIndent(record, "", "    ")
-		} else {
-			marshaled, err = json.Marshal(record)
+			var indented bytes.Buffer
+			json.Indent(&indented, buff.Bytes(), "", "    ")
+			indented.WriteByte('\n')
+			return indented.Bytes()
+		}
+		buff.WriteByte('\n')
+		// buffer is returned to pool and reused, so return copy of its content
+		return append([]byte(nil), buff.Bytes()...)
+	})
+}
+
+// appendJSONRecord writes record to buffer as compact JSON object, with
+// same keys as encoding/json would use for Record.
+func appendJSONRecord(buff *bytes.Buffer, record Record) error {
+	buff.WriteString(`{"time":`)
+	b := append(buff.AvailableBuffer(), '"')
+	b = record.Time.AppendFormat(b, time.RFC3339Nano)
+	buff.Write(append(b, '"'))
+	buff.WriteString(`,"level":`)
+	buff.Write(appendJSONString(buff.AvailableBuffer(), record.Level.String()))
+	buff.WriteString(`,"message":`)
+	buff.Write(appendJSONString(buff.AvailableBuffer(), record.Message))
+
+	buff.WriteString(`,"context":{`)
+	keys := make([]string, 0, len(record.Context))
+	for k := range record.Context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	first := true
+	appendKey := func(k string) {
+		if !first {
+			buff.WriteByte(',')
+		}
+		first = false
+		buff.Write(appendJSONString(buff.AvailableBuffer(), k))
+		buff.WriteByte(':')
+	}
+	for _, k := range keys {
+		appendKey(k)
+		if err := appendJSONValue(buff, record.Context[k]); err != nil {
+			return err
+		}
+	}
+	for _, field := range record.Fields {
+		if _, ok := record.Context[field.Key]; ok {
+			continue
+		}
+		appendKey(field.Key)
+		if err := appendJSONField(buff, field); err != nil {
+			return err
 		}
+	}
+	buff.WriteByte('}')
+
+	buff.WriteString(`,"file":`)
+	buff.Write(appendJSONString(buff.AvailableBuffer(), record.File))
+	buff.WriteString(`,"line":`)
+	buff.Write(strconv.AppendInt(buff.AvailableBuffer(), int64(record.Line), 10))
+	if len(record.Stack) > 0 {
+		stack, err := json.Marshal(record.Stack)
 		if err != nil {
-			marshaled, _ = json.Marshal(map[string]string{
-				"JSONError": err.Error(),
-			})
+			return err
 		}
-		marshaled = append(marshaled, '\n')
-		return marshaled
-	})
+		buff.WriteString(`,"stack":`)
+		buff.Write(stack)
+	}
+	buff.WriteByte('}')
+	return nil
+}
+
+// appendJSONValue writes context value to buffer. Errors are written as
+// ErrorDetails and all other values as strings.
+func appendJSONValue(buff *bytes.Buffer, value interface{}) error {
+	if err, ok := value.(error); ok {
+		details, marshalErr := json.Marshal(jsonErrorDetails(DescribeError(err)))
+		if marshalErr != nil {
+			return marshalErr
+		}
+		buff.Write(details)
+		return nil
+	}
+	buff.Write(appendJSONString(buff.AvailableBuffer(), fmt.Sprintf("%+v", value)))
+	return nil
+}
+
+// appendJSONField writes field value to buffer. Like context values, all
+// fields except errors are written as strings.
+func appendJSONField(buff *bytes.Buffer, field Field) error {
+	switch field.Type {
+	case StringType:
+		buff.Write(appendJSONString(buff.AvailableBuffer(), field.String))
+	case ErrorType:
+		return appendJSONValue(buff, field.Interface)
+	case ObjectType:
+		buff.Write(appendJSONString(buff.AvailableBuffer(), logfmtValue(field.Interface)))
+	default:
+		// text of other types never needs escaping
+		b := append(buff.AvailableBuffer(), '"')
+		b = field.AppendText(b)
+		buff.Write(append(b, '"'))
+	}
+	return nil
+}
+
+// appendJSONString appends s to dst as JSON string, escaped in same way
+// as encoding/json does it.
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// line and paragraph separators are valid JSON, but not JavaScript
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// jsonValue converts context value to value that is JSON serializable.
+// Errors are converted to ErrorDetails and all other values to strings.
+func jsonValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return jsonErrorDetails(DescribeError(err))
+	}
+	return fmt.Sprintf("%+v", value)
+}
+
+// jsonErrorDetails makes sure that fields of error and all its causes are
+// JSON serializable.
+func jsonErrorDetails(details ErrorDetails) ErrorDetails {
+	if details.Fields != nil {
+		fields := make(Ctx, len(details.Fields))
+		for k, v := range details.Fields {
+			fields[k] = jsonValue(v)
+		}
+		details.Fields = fields
+	}
+	for i, cause := range details.Causes {
+		details.Causes[i] = jsonErrorDetails(cause)
+	}
+	return details
 }
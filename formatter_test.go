@@ -0,0 +1,21 @@
+package ligno
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormattedRecordNotReused(t *testing.T) {
+	for name, formatter := range map[string]Formatter{
+		"simple":   SimpleFormat(),
+		"terminal": ThemedTerminalFormat(NoColorTheme),
+	} {
+		first := formatter.Format(Record{Message: "first"})
+		for i := 0; i < 10; i++ {
+			formatter.Format(Record{Message: "second"})
+		}
+		if !strings.Contains(string(first), "first") {
+			t.Errorf("Expected %s formatter output not to be overwritten by later records, got %q", name, first)
+		}
+	}
+}
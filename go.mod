@@ -11,6 +11,7 @@ require (
 	github.com/mgutz/logxi v0.0.0-20161027140823-aebf8a7d67ab
 	github.com/sirupsen/logrus v1.9.3
 	go.delic.rs/ligno v0.0.0-20170418230247-93f5fbffb114
+	gopkg.in/yaml.v3 v3.0.1
 	resenje.org/logging v0.1.8
 )
 
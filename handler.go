@@ -1,12 +1,15 @@
 package ligno
 
 import (
+	"errors"
 	"io"
 	"os"
 	"sync"
 )
 
 // Handler processes log records and writes them to appropriate destination.
+// Handlers that keep records after Handle returns must keep copies created
+// with Record.Detach.
 type Handler interface {
 	// Handle processes provided log record.
 	Handle(Record) error
@@ -19,6 +22,22 @@ type HandlerCloser interface {
 	Close()
 }
 
+// HandlerFlusher is interface that allows handlers that buffer records to
+// be flushed. If handler implements this interface, Flush will be called
+// when logger is waited for, after all queued records are processed.
+type HandlerFlusher interface {
+	Flush() error
+}
+
+// flushHandler flushes provided handler if it implements HandlerFlusher
+// interface.
+func flushHandler(handler Handler) error {
+	if handlerFlusher, ok := handler.(HandlerFlusher); ok {
+		return handlerFlusher.Flush()
+	}
+	return nil
+}
+
 // HandlerFunc is function that implements Handler interface.
 type HandlerFunc func(Record) error
 
@@ -41,12 +60,36 @@ type Predicate func(Record) bool
 // FilterHandler checks records if by using predicate to check if they should
 // be processed and only if they do, record is passed to provided handler.
 func FilterHandler(predicate Predicate, handler Handler) Handler {
-	return HandlerFunc(func(record Record) error {
-		if predicate(record) {
-			return handler.Handle(record)
-		}
-		return nil
-	})
+	return &filterHandler{
+		predicate: predicate,
+		handler:   handler,
+	}
+}
+
+// filterHandler passes only records that satisfy predicate to handler.
+type filterHandler struct {
+	predicate Predicate
+	handler   Handler
+}
+
+// Handle passes record to internal handler if predicate allows it.
+func (fh *filterHandler) Handle(record Record) error {
+	if fh.predicate(record) {
+		return fh.handler.Handle(record)
+	}
+	return nil
+}
+
+// Close closes internal handler if it implements HandlerCloser interface.
+func (fh *filterHandler) Close() {
+	if handlerCloser, ok := fh.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// Flush flushes internal handler if it implements HandlerFlusher interface.
+func (fh *filterHandler) Flush() error {
+	return flushHandler(fh.handler)
 }
 
 // FilterLevelHandler is FilterHandler with default predicate function that filters
@@ -64,12 +107,15 @@ type combiningHandler struct {
 }
 
 // Handle processes record by passing it to all internal handler of this handler.
+// Errors from all handlers that failed are joined in returned error.
 func (ch *combiningHandler) Handle(record Record) error {
-	var err error
+	var errs []error
 	for _, h := range ch.Handlers {
-		err = h.Handle(record)
+		if err := h.Handle(record); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return err
+	return errors.Join(errs...)
 }
 
 // Close closes all internal handlers if they implement HandlerCloser interface.
@@ -81,6 +127,19 @@ func (ch *combiningHandler) Close() {
 	}
 }
 
+// Flush flushes all internal handlers that implement HandlerFlusher
+// interface. Errors from all handlers that failed are joined in returned
+// error.
+func (ch *combiningHandler) Flush() error {
+	var errs []error
+	for _, h := range ch.Handlers {
+		if err := flushHandler(h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // CombiningHandler creates and returns handler that passes records to all
 // provided handlers.
 func CombiningHandler(handlers ...Handler) Handler {
@@ -104,14 +163,33 @@ type fileHandler struct {
 	f         *os.File
 }
 
+// open opens file for appending if it is not already opened.
+func (fh *fileHandler) open() error {
+	if fh.f != nil {
+		return nil
+	}
+	f, err := os.OpenFile(fh.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	fh.f = f
+	return nil
+}
+
+// close closes file if it is opened.
+func (fh *fileHandler) close() error {
+	if fh.f == nil {
+		return nil
+	}
+	err := fh.f.Close()
+	fh.f = nil
+	return err
+}
+
 // Handle writes record to file.
 func (fh *fileHandler) Handle(record Record) error {
-	if fh.f == nil {
-		f, err := os.OpenFile(fh.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-		if err != nil {
-			panic(err)
-		}
-		fh.f = f
+	if err := fh.open(); err != nil {
+		return err
 	}
 
 	_, err := fh.f.Write(fh.formatter.Format(record))
@@ -120,7 +198,7 @@ func (fh *fileHandler) Handle(record Record) error {
 
 // Close closes file were records are being written.
 func (fh *fileHandler) Close() {
-	fh.f.Close()
+	fh.close()
 }
 
 // NullHandler returns handler that discards all records.
@@ -0,0 +1,65 @@
+package ligno
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Lazy is context value that is computed only when record is processed.
+// Function is called in worker goroutine of logger, so expensive values do
+// not slow down caller, and it is called only if record is not discarded
+// because of its level. Example:
+//
+//	l.Info("Request processed", "stats", ligno.Lazy(func() interface{} {
+//		return collectStats()
+//	}))
+type Lazy func() interface{}
+
+// LogValue calls function and returns its result.
+func (lz Lazy) LogValue() interface{} {
+	return lz()
+}
+
+// LogValuer is implemented by context values that should be logged as
+// different value. Like Lazy, LogValue is called in worker goroutine of
+// logger, once per record.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// maxLogValueDepth is max number of LogValue calls for single value, in
+// case that LogValue returns another LogValuer.
+const maxLogValueDepth = 100
+
+// resolveContext replaces all LogValuer (and slog.LogValuer) values in
+// record context with values they resolve to. Context is modified in place,
+// so it must not be shared with records that are not yet resolved.
+func resolveContext(ctx Ctx) {
+	for k, v := range ctx {
+		switch v.(type) {
+		case LogValuer, slog.LogValuer:
+			ctx[k] = resolveValue(v)
+		}
+	}
+}
+
+// resolveValue calls LogValue until value that is not LogValuer is
+// returned. Panics in LogValue are recovered and reported as value.
+func resolveValue(value interface{}) (resolved interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			resolved = fmt.Sprintf("!PANIC in LogValue: %v", r)
+		}
+	}()
+	for i := 0; i < maxLogValueDepth; i++ {
+		switch v := value.(type) {
+		case LogValuer:
+			value = v.LogValue()
+		case slog.LogValuer:
+			value = slog.AnyValue(v).Resolve().Any()
+		default:
+			return value
+		}
+	}
+	return value
+}
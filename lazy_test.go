@@ -0,0 +1,49 @@
+package ligno
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type secretUser struct {
+	name string
+}
+
+func (u secretUser) LogValue() interface{} {
+	return "user:" + u.name
+}
+
+func TestLazyResolvedOnce(t *testing.T) {
+	parentHandler := MemoryHandler(JSONFormat(false))
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            parentHandler,
+		PreventPropagation: true,
+	})
+	defer parent.StopAndWait()
+	childHandler := MemoryHandler(JSONFormat(false))
+	child := parent.SubLoggerOptions("child", LoggerOptions{
+		Handler: childHandler,
+		Level:   INFO,
+	})
+	defer child.StopAndWait()
+
+	var calls int32
+	lazy := Lazy(func() interface{} {
+		return atomic.AddInt32(&calls, 1)
+	})
+	child.Debug("discarded", "value", lazy)
+	child.Info("logged", "value", lazy, "user", secretUser{"bob"})
+	parent.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected lazy value to be resolved once, got %d calls", calls)
+	}
+	for _, handler := range []InspectHandler{childHandler, parentHandler} {
+		messages := handler.Messages()
+		if len(messages) != 1 || !strings.Contains(messages[0], `"value":"1"`) ||
+			!strings.Contains(messages[0], `"user":"user:bob"`) {
+			t.Errorf("Expected resolved values, got %v", messages)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -57,6 +58,25 @@ func getLevelFromName(name string) (level Level) {
 	return name2Level[name]
 }
 
+// ParseLevel returns level with provided name. Besides names of registered
+// levels, numeric rank of level is accepted as well.
+func ParseLevel(name string) (Level, error) {
+	mu.RLock()
+	level, ok := name2Level[strings.ToUpper(name)]
+	if !ok {
+		level, ok = name2Level[name]
+	}
+	mu.RUnlock()
+	if ok {
+		return level, nil
+	}
+	rank, err := strconv.ParseUint(name, 10, 0)
+	if err != nil {
+		return NOTSET, fmt.Errorf("unknown level: %s", name)
+	}
+	return Level(rank), nil
+}
+
 // AddLevel add new level to system with provided name and rank.
 // It is forbidden to register levels that already exist.
 func AddLevel(name string, rank Level) (Level, error) {
@@ -0,0 +1,211 @@
+package ligno
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogfmtOptions holds configuration for LogfmtFormat. Empty value is valid
+// and uses default time format and key names. Setting key name to "-"
+// omits that value from output.
+type LogfmtOptions struct {
+	// TimeFormat is layout used for formatting time. Default is time.RFC3339Nano.
+	TimeFormat string
+	// TimeKey is name of key for record time. Default is "time".
+	TimeKey string
+	// LevelKey is name of key for record level. Default is "level".
+	LevelKey string
+	// MessageKey is name of key for record message. Default is "msg".
+	MessageKey string
+	// LoggerKey is name of key for full name of logger. Default is "logger".
+	LoggerKey string
+	// CallerKey is name of key for file and line where record was created.
+	// Default is "caller".
+	CallerKey string
+	// StackKey is name of key for stack trace, if record has it. Frames
+	// are separated by new line. Default is "stack".
+	StackKey string
+}
+
+// withDefaults returns copy of options with default values set for all
+// values that are not set.
+func (o LogfmtOptions) withDefaults() LogfmtOptions {
+	setDefault := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	setDefault(&o.TimeFormat, time.RFC3339Nano)
+	setDefault(&o.TimeKey, "time")
+	setDefault(&o.LevelKey, "level")
+	setDefault(&o.MessageKey, "msg")
+	setDefault(&o.LoggerKey, "logger")
+	setDefault(&o.CallerKey, "caller")
+	setDefault(&o.StackKey, "stack")
+	return o
+}
+
+// LogfmtFormat returns formatter that formats records in logfmt format,
+// as space separated key=value pairs in single line. Values are quoted when
+// needed and context keys are written in sorted order after time, level,
+// message, logger and caller, followed by fields in order in which they were
+// provided. Stack trace, if record has it, is last.
+func LogfmtFormat(options LogfmtOptions) Formatter {
+	options = options.withDefaults()
+	return FormatterFunc(func(record Record) []byte {
+		buff := buffPool.Get()
+		defer buffPool.Put(buff)
+		if options.TimeKey != "-" {
+			appendLogfmtPair(buff, options.TimeKey, record.Time.Format(options.TimeFormat))
+		}
+		if options.LevelKey != "-" {
+			appendLogfmtPair(buff, options.LevelKey, record.Level.String())
+		}
+		if options.MessageKey != "-" {
+			appendLogfmtPair(buff, options.MessageKey, record.Message)
+		}
+		if options.LoggerKey != "-" && record.Logger != nil {
+			if name := record.Logger.FullName(); name != "" {
+				appendLogfmtPair(buff, options.LoggerKey, name)
+			}
+		}
+		if options.CallerKey != "-" && record.File != "" {
+			appendLogfmtPair(buff, options.CallerKey, record.File+":"+strconv.Itoa(record.Line))
+		}
+
+		ctx := record.Context
+		keys := make([]string, 0, len(ctx))
+		for k := range ctx {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			appendLogfmtPair(buff, k, ctx[k])
+		}
+		for _, field := range record.Fields {
+			appendLogfmtField(buff, field)
+		}
+		if options.StackKey != "-" && len(record.Stack) > 0 {
+			appendLogfmtPair(buff, options.StackKey, logfmtStack(record.Stack))
+		}
+		buff.WriteRune('\n')
+		// buffer is returned to pool and reused, so return copy of its content
+		return append([]byte(nil), buff.Bytes()...)
+	})
+}
+
+// appendLogfmtPair writes key=value pair to buffer, separated with space
+// from previous content.
+func appendLogfmtPair(buff *bytes.Buffer, key string, value interface{}) {
+	if buff.Len() > 0 {
+		buff.WriteRune(' ')
+	}
+	buff.WriteString(logfmtKey(key))
+	buff.WriteRune('=')
+	buff.WriteString(logfmtQuote(logfmtValue(value)))
+}
+
+// appendLogfmtField writes field as key=value pair to buffer, separated
+// with space from previous content. Value is encoded directly into buffer
+// and quoted afterwards only if needed.
+func appendLogfmtField(buff *bytes.Buffer, field Field) {
+	if buff.Len() > 0 {
+		buff.WriteRune(' ')
+	}
+	buff.WriteString(logfmtKey(field.Key))
+	buff.WriteRune('=')
+	start := buff.Len()
+	buff.Write(field.AppendText(buff.AvailableBuffer()))
+	value := buff.Bytes()[start:]
+	if len(value) == 0 || bytes.IndexFunc(value, needsQuote) >= 0 {
+		quoted := strconv.Quote(string(value))
+		buff.Truncate(start)
+		buff.WriteString(quoted)
+	}
+}
+
+// logfmtKey returns key with all characters that are not allowed in
+// logfmt keys replaced with underscore.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	if strings.IndexFunc(key, needsQuote) < 0 {
+		return key
+	}
+	return strings.Map(func(r rune) rune {
+		if needsQuote(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtQuote quotes and escapes value if it contains characters that
+// require quoting.
+func logfmtQuote(value string) string {
+	if value != "" && strings.IndexFunc(value, needsQuote) < 0 {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// logfmtStack returns stack trace in single string, each frame in its own
+// line.
+func logfmtStack(stack []StackFrame) string {
+	var b strings.Builder
+	for i, frame := range stack {
+		if i > 0 {
+			b.WriteRune('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteRune(' ')
+		b.WriteString(frame.File)
+		b.WriteRune(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+	}
+	return b.String()
+}
+
+// logfmtValue converts provided value to its string representation.
+// Errors and fmt.Stringer values are rendered using their methods, while
+// nested values (maps, slices and structs) are rendered as JSON.
+func logfmtValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if marshaled, err := json.Marshal(value); err == nil {
+			return string(marshaled)
+		}
+	}
+	return fmt.Sprintf("%+v", value)
+}
@@ -0,0 +1,44 @@
+package ligno
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogfmtFormat(t *testing.T) {
+	recordTime := time.Date(2016, 1, 7, 1, 6, 10, 0, time.UTC)
+	record := Record{
+		Time:    recordTime,
+		Level:   INFO,
+		Message: "user logged in",
+		Context: Ctx{
+			"user":     "john",
+			"quote":    `say "hi"`,
+			"err":      errors.New("failed"),
+			"duration": time.Second,
+			"nested":   map[string]int{"a": 1},
+			"count":    3,
+			"empty":    "",
+			"bad key":  nil,
+		},
+		File: "main.go",
+		Line: 12,
+	}
+	expected := `time=2016-01-07T01:06:10Z level=INFO msg="user logged in" caller=main.go:12 ` +
+		`bad_key=nil count=3 duration=1s empty="" err=failed nested="{\"a\":1}" quote="say \"hi\"" user=john` + "\n"
+	if got := string(LogfmtFormat(LogfmtOptions{}).Format(record)); got != expected {
+		t.Errorf("Unexpected logfmt output:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	custom := LogfmtFormat(LogfmtOptions{
+		TimeFormat: time.Kitchen,
+		TimeKey:    "ts",
+		MessageKey: "message",
+		CallerKey:  "-",
+	})
+	expected = "ts=1:06AM level=INFO message=hello\n"
+	if got := string(custom.Format(Record{Time: recordTime, Level: INFO, Message: "hello", File: "main.go"})); got != expected {
+		t.Errorf("Unexpected logfmt output: %q, expected %q", got, expected)
+	}
+}
@@ -55,47 +55,53 @@ func (l *Logger) Write(p []byte) (n int, err error) {
 }
 
 // Fatal formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func (l *Logger) Fatal(v ...interface{}) {
 	l.Log(2, CRITICAL, fmt.Sprint(v...))
-	os.Exit(1)
+	l.exit(1)
 }
 
 // Fatalf formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func (l *Logger) Fatalf(format string, v ...interface{}) {
 	l.Log(2, CRITICAL, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	l.exit(1)
 }
 
 // Fatalln formats message according to stdlib rules, logs it in CRITICAL level
-// and exists application.
+// and exists application once record is processed (see SetExitTimeout
+// and SetExitFunc).
 func (l *Logger) Fatalln(v ...interface{}) {
 	l.Log(2, CRITICAL, fmt.Sprintln(v...))
-	os.Exit(1)
+	l.exit(1)
 }
 
 // Panic formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func (l *Logger) Panic(v ...interface{}) {
 	s := fmt.Sprint(v...)
 	l.Log(2, CRITICAL, s)
+	l.waitPropagated()
 	panic(s)
 }
 
 // Panicf formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func (l *Logger) Panicf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
 	l.Log(2, CRITICAL, s)
+	l.waitPropagated()
 	panic(s)
 }
 
 // Panicln formats message according to stdlib rules, logs it in CRITICAL level
-// and panics.
+// and panics once record is processed (see SetExitTimeout).
 func (l *Logger) Panicln(v ...interface{}) {
 	s := fmt.Sprintln(v...)
 	l.Log(2, CRITICAL, s)
+	l.waitPropagated()
 	panic(s)
 }
 
@@ -119,10 +125,40 @@ func WaitAllTimeout(t time.Duration) bool {
 	return rootLogger.WaitTimeout(t)
 }
 
+// defaultSynchronous is set to 1 if loggers without explicitly set mode
+// should be synchronous.
+var defaultSynchronous uint32
+
+// Modes of logger, stored in synchronous field of logger.
+const (
+	// syncDefault is mode of logger that follows default set with package
+	// level SetSynchronous.
+	syncDefault uint32 = iota
+	syncEnabled
+	syncDisabled
+)
+
+// SetSynchronous changes default mode, used by all existing and future
+// loggers whose mode is not set explicitly with LoggerOptions.Synchronous,
+// Logger.SetSynchronous or configuration. In synchronous mode records are
+// processed and handled on calling goroutine, which is useful for command
+// line tools and tests that need deterministic output. See
+// Logger.SetSynchronous for restrictions of synchronous mode.
+func SetSynchronous(synchronous bool) {
+	var val uint32
+	if synchronous {
+		val = 1
+	}
+	atomic.StoreUint32(&defaultSynchronous, val)
+}
+
 type loggerState uint8
 
 const (
 	loggerRunning loggerState = iota
+	// loggerDraining is state of logger that is being shut down. It does
+	// not accept new records, except ones propagated from its children.
+	loggerDraining
 	loggerStopped
 )
 
@@ -132,6 +168,15 @@ const (
 // messages and context (set of key-value pairs that will be include
 // in every log record).
 type Logger struct {
+	*loggerCore
+	// bound is context bound to this logger with With or WithCtx. It is
+	// merged into every record logged through this logger.
+	bound Ctx
+}
+
+// loggerCore holds state of logger (queues, workers, handler, relationships)
+// that is shared between logger and all views created from it with With.
+type loggerCore struct {
 	// name is name of this logger.
 	name string
 	// Context in which logger is operating. Basically, this is set of
@@ -166,24 +211,59 @@ type Logger struct {
 	rawRecords chan Record
 	// records is channel for queueing and buffering log records.
 	records chan Record
-	// notifyFinished is channel of channels. When someone wants to be notified
-	// when logger processed all queued records, it sends channel that will be
-	// closed after last queued record is processed to notifyFinished.
-	notifyFinished chan chan struct{}
+	// finished holds channels of parties that want to be notified when
+	// logger processes all queued records. Channels are closed after last
+	// queued record is processed.
+	finished struct {
+		sync.Mutex
+		waiters []chan struct{}
+	}
 	// toProcess is number of messages left to process in this logger.
 	toProcess int32
+	// dropped is number of records discarded because buffer was full.
+	dropped uint64
+	// accepted is number of records accepted for processing.
+	accepted uint64
+	// handlerErrors is number of records that handler failed to process.
+	handlerErrors uint64
+	// stats holds statistics about processed records, updated by worker
+	// goroutine.
+	stats loggerStats
+	// overflowPolicy defines what happens when buffer for records is full.
+	overflowPolicy OverflowPolicy
+	// overflowTimeout is max time to wait for space in buffer when
+	// OverflowBlockWithTimeout policy is used.
+	overflowTimeout time.Duration
+	// errorHandler is called when handler fails to process record.
+	errorHandler ErrorHandler
 	// state represents state in which logger is currently
 	state struct {
 		sync.RWMutex
 		val loggerState
 	}
-	// level is lowest level that this logger will process
-	level Level
-	// Flag that indicates that file and line of place where logging took place
-	// should be kept.
-	includeFileAndLine bool
+	// level is lowest level that this logger will process. It is accessed
+	// atomically, since it can be changed while logger is running.
+	level uint64
+	// includeFileAndLine is set to 1 if file and line of place where logging
+	// took place should be kept. It is accessed atomically.
+	includeFileAndLine uint32
+	// stackTraceLevel is lowest level of records for which stack trace is
+	// captured. NOTSET disables capturing. It is accessed atomically.
+	stackTraceLevel uint64
+	// processors transform records after their context is built.
+	processors []Processor
+	// synchronous holds mode of logger, one of sync constants, which
+	// defines if records are processed on calling goroutine instead of
+	// worker goroutines. It is accessed atomically.
+	synchronous uint32
+	// handling serializes calls to handler.
+	handling sync.Mutex
 }
 
+// Processor transforms record before it is passed to handler, for example
+// to remove sensitive data from it.
+type Processor func(Record) Record
+
 // LoggerOptions is container for configuration options for logger instances.
 // Empty value is valid for initializing logger.
 type LoggerOptions struct {
@@ -191,7 +271,8 @@ type LoggerOptions struct {
 	Context Ctx
 	// Handler for processing records.
 	Handler Handler
-	// Level is minimal level that logger will process.
+	// Level is minimal level that logger will process. NOTSET means that
+	// level is inherited from parent logger.
 	Level Level
 	// BufferSize is size of buffer for records that will be process async.
 	BufferSize int
@@ -202,6 +283,29 @@ type LoggerOptions struct {
 	// should be kept. Note that this is expensive, so use with care. If this
 	// information will be shown depends on formatter.
 	IncludeFileAndLine bool
+	// StackTraceLevel is lowest level of records for which stack trace of
+	// place where logging took place is captured. Default value (NOTSET)
+	// disables capturing. Like IncludeFileAndLine, this is expensive.
+	StackTraceLevel Level
+	// OverflowPolicy defines what happens with new records when buffer is
+	// full. Default is to block until there is space in buffer.
+	OverflowPolicy OverflowPolicy
+	// OverflowTimeout is max time logging will block when OverflowPolicy is
+	// OverflowBlockWithTimeout. If not set, 100ms is used.
+	OverflowTimeout time.Duration
+	// ErrorHandler is called when handler fails to process record. If not
+	// set, error handler set with SetErrorHandler is used.
+	ErrorHandler ErrorHandler
+	// Processors are applied, in order, to every record logged with this
+	// logger after its context is built and before it is handled or
+	// propagated to parent. They are run in worker goroutine or, in
+	// synchronous mode, on calling goroutine.
+	Processors []Processor
+	// Synchronous is flag that indicates that records should be processed
+	// and handled on calling goroutine, so they are written before logging
+	// call returns. If not set, logger follows default mode set with
+	// SetSynchronous. See Logger.SetSynchronous for restrictions.
+	Synchronous bool
 }
 
 // createLogger creates new instance of logger, initializes all values based
@@ -215,21 +319,34 @@ func createLogger(name string, options LoggerOptions) *Logger {
 	} else {
 		buffSize = 1024
 	}
-	l := &Logger{
-		name:               name,
-		context:            options.Context,
-		records:            make(chan Record, buffSize),
-		rawRecords:         make(chan Record, buffSize),
-		notifyFinished:     make(chan chan struct{}),
-		handler:            rh,
-		level:              options.Level,
-		includeFileAndLine: options.IncludeFileAndLine,
+	overflowTimeout := options.OverflowTimeout
+	if overflowTimeout <= 0 {
+		overflowTimeout = defaultOverflowTimeout
 	}
+	l := &Logger{loggerCore: &loggerCore{
+		name:            name,
+		context:         options.Context,
+		records:         make(chan Record, buffSize),
+		rawRecords:      make(chan Record, buffSize),
+		handler:         rh,
+		level:           uint64(options.Level),
+		stackTraceLevel: uint64(options.StackTraceLevel),
+		overflowPolicy:  options.OverflowPolicy,
+		overflowTimeout: overflowTimeout,
+		errorHandler:    options.ErrorHandler,
+		processors:      options.Processors,
+	}}
 	// no need to lock access to state here since we just created logger
 	// and nobody can use it anywhere else at the moment.
 	l.state.val = loggerRunning
 	l.relationship.children = make(map[string]*Logger)
 	l.relationship.preventPropagation = options.PreventPropagation
+	if options.IncludeFileAndLine {
+		l.includeFileAndLine = 1
+	}
+	if options.Synchronous {
+		l.synchronous = syncEnabled
+	}
 	go l.handle()
 	go l.processRecords()
 	return l
@@ -298,14 +415,15 @@ func GetLoggerOptions(name string, options LoggerOptions) *Logger {
 }
 
 func (l *Logger) addChild(child *Logger) {
-	l.relationship.Lock()
-	//	l.relationship.children = append(l.relationship.children, child)
-	l.relationship.children[child.name] = child
-	l.relationship.Unlock()
-
+	// set parent first, so that child is fully initialized when it becomes
+	// reachable through its parent
 	child.relationship.Lock()
 	child.relationship.parent = l
 	child.relationship.Unlock()
+
+	l.relationship.Lock()
+	l.relationship.children[child.name] = child
+	l.relationship.Unlock()
 }
 
 func (l *Logger) removeChild(child *Logger) {
@@ -324,9 +442,156 @@ func (l *Logger) Handler() Handler {
 	return l.handler.Handler()
 }
 
-// Level returns minimal level that this logger will process.
+// Level returns minimal level set to this logger. NOTSET means that level
+// is inherited from parent, see EffectiveLevel.
 func (l *Logger) Level() Level {
-	return l.level
+	return Level(atomic.LoadUint64(&l.level))
+}
+
+// SetLevel sets minimal level of records that this logger creates. Records
+// propagated from children are not filtered by it. It is safe to change
+// level while logger is in use. Setting NOTSET makes logger inherit level
+// from its parent.
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreUint64(&l.level, uint64(level))
+}
+
+// SetLevelRecursive sets provided level to this logger and all its
+// descendants.
+func (l *Logger) SetLevelRecursive(level Level) {
+	l.SetLevel(level)
+	for _, child := range l.children() {
+		child.SetLevelRecursive(level)
+	}
+}
+
+// EffectiveLevel returns minimal level that this logger will process. It is
+// level of this logger, or if it is NOTSET, level of nearest ancestor that
+// has level set.
+func (l *Logger) EffectiveLevel() Level {
+	for current := l; current != nil; current = current.relationship.parent {
+		if level := current.Level(); level != NOTSET {
+			return level
+		}
+	}
+	return NOTSET
+}
+
+// IncludeFileAndLine returns true if file and line of place where logging
+// took place are kept in records.
+func (l *Logger) IncludeFileAndLine() bool {
+	return atomic.LoadUint32(&l.includeFileAndLine) == 1
+}
+
+// SetIncludeFileAndLine sets flag that indicates if file and line of place
+// where logging took place are kept in records. It is safe to change it
+// while logger is in use.
+func (l *Logger) SetIncludeFileAndLine(include bool) {
+	var val uint32
+	if include {
+		val = 1
+	}
+	atomic.StoreUint32(&l.includeFileAndLine, val)
+}
+
+// StackTraceLevel returns lowest level of records for which stack trace is
+// captured. NOTSET means that stack traces are not captured.
+func (l *Logger) StackTraceLevel() Level {
+	return Level(atomic.LoadUint64(&l.stackTraceLevel))
+}
+
+// SetStackTraceLevel sets lowest level of records for which stack trace is
+// captured. Setting NOTSET disables capturing. It is safe to change it while
+// logger is in use.
+func (l *Logger) SetStackTraceLevel(level Level) {
+	atomic.StoreUint64(&l.stackTraceLevel, uint64(level))
+}
+
+// IsSynchronous returns true if records logged with this logger are
+// processed on calling goroutine.
+func (l *Logger) IsSynchronous() bool {
+	switch atomic.LoadUint32(&l.synchronous) {
+	case syncEnabled:
+		return true
+	case syncDisabled:
+		return false
+	default:
+		return atomic.LoadUint32(&defaultSynchronous) == 1
+	}
+}
+
+// SetSynchronous changes mode of this logger. In synchronous mode records
+// are processed and handled on calling goroutine, while records propagated
+// to parent are processed in mode of parent, so synchronous and async
+// loggers can be mixed in one tree. Records queued before switching to
+// synchronous mode are still processed by worker goroutines. Once set,
+// mode of logger is no longer changed by package level SetSynchronous.
+// Handler calls are serialized, so in synchronous mode handler of logger
+// (or of synchronous logger records are propagated to) must not log with
+// that same logger, since that would deadlock. This includes handlers that
+// forward records to slog handler bridged back to same logger. Error
+// handler is not subject to this restriction.
+func (l *Logger) SetSynchronous(synchronous bool) {
+	val := syncDisabled
+	if synchronous {
+		val = syncEnabled
+	}
+	atomic.StoreUint32(&l.synchronous, val)
+}
+
+// PreventPropagation returns true if records are not passed to parent logger.
+func (l *Logger) PreventPropagation() bool {
+	l.relationship.RLock()
+	defer l.relationship.RUnlock()
+	return l.relationship.preventPropagation
+}
+
+// SetPreventPropagation sets flag that indicates if records should be
+// passed to parent logger for processing. Change applies to records that
+// are processed after this call.
+func (l *Logger) SetPreventPropagation(prevent bool) {
+	l.relationship.Lock()
+	defer l.relationship.Unlock()
+	l.relationship.preventPropagation = prevent
+}
+
+// walk calls provided function for this logger and all its descendants,
+// parents being visited before children.
+func (l *Logger) walk(fn func(*Logger)) {
+	fn(l)
+	for _, child := range l.children() {
+		child.walk(fn)
+	}
+}
+
+// findLogger returns existing logger with provided full name without
+// creating it. Empty name denotes root logger.
+func findLogger(name string) (*Logger, bool) {
+	current := rootLogger
+	if name == "" {
+		return current, true
+	}
+	for _, part := range strings.Split(name, ".") {
+		current.relationship.RLock()
+		child, ok := current.relationship.children[part]
+		current.relationship.RUnlock()
+		if !ok {
+			return nil, false
+		}
+		current = child
+	}
+	return current, true
+}
+
+// children returns snapshot of direct children of this logger.
+func (l *Logger) children() []*Logger {
+	l.relationship.RLock()
+	defer l.relationship.RUnlock()
+	children := make([]*Logger, 0, len(l.relationship.children))
+	for _, child := range l.relationship.children {
+		children = append(children, child)
+	}
+	return children
 }
 
 // Name returns name of this logger.
@@ -349,39 +614,63 @@ func (l *Logger) FullName() string {
 
 // handle is log record processor which takes records from chan and invokes all handlers.
 func (l *Logger) handle() {
-	var notifyFinished chan struct{}
-	for {
-		select {
-		case record, ok := <-l.records:
-			if !ok {
-				return
-			}
-			l.handler.Handle(record)
-
-			atomic.AddInt32(&l.toProcess, -1)
-			// if count dropped to 0, close notification channel
-			if atomic.LoadInt32(&l.toProcess) == 0 && notifyFinished != nil {
-				close(notifyFinished)
-				// reset notification channel
-				notifyFinished = nil
-			}
-		case notifyFinished = <-l.notifyFinished:
-			// check count right away and notify that processing is done if possible
-			if atomic.LoadInt32(&l.toProcess) == 0 {
-				close(notifyFinished)
-				// reset notification channel
-				notifyFinished = nil
-			}
+	for record := range l.records {
+		l.handleRecord(record)
+		l.recordDone()
+	}
+}
+
+// handleRecord passes record to handler and reports failure to error
+// handler. Handler calls are serialized, since handlers are called from
+// worker goroutine and, in synchronous mode, from callers. Error handler is
+// called after lock is released, so that it can log with this logger.
+func (l *Logger) handleRecord(record Record) {
+	l.handling.Lock()
+	l.stats.observe(record)
+	err := l.callHandler(record)
+	l.handling.Unlock()
+	if err != nil {
+		atomic.AddUint64(&l.handlerErrors, 1)
+		l.getErrorHandler()(record, l.Handler(), err)
+	}
+	record.releaseFields()
+}
+
+// recordDone marks one record as processed (or discarded) and notifies
+// all waiting parties if there are no more records to process.
+func (l *Logger) recordDone() {
+	if atomic.AddInt32(&l.toProcess, -1) == 0 {
+		l.finished.Lock()
+		for _, done := range l.finished.waiters {
+			close(done)
 		}
+		l.finished.waiters = nil
+		l.finished.Unlock()
 	}
 }
 
+// notifyWhenFinished registers channel that will be closed when logger
+// processes all queued records. If there are no queued records, channel
+// is closed right away.
+func (l *Logger) notifyWhenFinished(done chan struct{}) {
+	l.finished.Lock()
+	defer l.finished.Unlock()
+	if atomic.LoadInt32(&l.toProcess) == 0 {
+		close(done)
+		return
+	}
+	l.finished.waiters = append(l.finished.waiters, done)
+}
+
 // buildContext builds context from this logger ant all its parents.
 // TODO: Maybe keep context stating per logger, so that we do not have to build it all the time
 func (l *Logger) buildContext() Ctx {
-	if l.relationship.parent == nil {
+	if l.relationship.parent == nil || len(l.relationship.parent.context) == 0 {
 		return l.context
 	}
+	if len(l.context) == 0 {
+		return l.relationship.parent.context
+	}
 	return l.relationship.parent.context.merge(l.context)
 }
 
@@ -394,41 +683,91 @@ func (l *Logger) processRecords() {
 			if !ok {
 				return
 			}
-
-			record.Context = l.buildContext().merge(record.Context)
-
+			record = l.prepare(record)
+			// record is propagated before it is queued for handling, so that
+			// parent takes its reference to record fields before this logger
+			// releases its own
+			l.propagate(record)
 			l.records <- record
-			if !l.relationship.preventPropagation && l.relationship.parent != nil {
-				l.relationship.parent.log(-1, record)
-			}
 		}
 	}
 }
 
+// processSync processes record on calling goroutine, for loggers in
+// synchronous mode.
+func (l *Logger) processSync(record Record) {
+	record = l.prepare(record)
+	l.propagate(record)
+	l.handleRecord(record)
+}
+
+// prepare merges context of this logger into record, resolves lazy values
+// and applies processors.
+func (l *Logger) prepare(record Record) Record {
+	// merging is skipped if there is no context, so that records logged
+	// with fields only do not allocate context map
+	if ctx := l.buildContext(); len(ctx) > 0 || len(record.Context) > 0 {
+		record.Context = ctx.merge(record.Context)
+		// values propagated from children are already resolved, so only
+		// lazy values from context of this logger are resolved
+		resolveContext(record.Context)
+	}
+	resolveFields(record.Fields)
+	for _, process := range l.processors {
+		record = process(record)
+	}
+	return record
+}
+
+// propagate passes record to parent logger, unless propagation is prevented.
+func (l *Logger) propagate(record Record) {
+	if !l.PreventPropagation() && l.relationship.parent != nil {
+		l.relationship.parent.log(-1, record)
+	}
+}
+
 // log creates record suitable for processing and sends it to messages chan.
 func (l *Logger) log(calldepth int, record Record) {
 	l.state.RLock()
 	defer l.state.RUnlock()
-	if l.state.val == loggerStopped || !l.IsEnabledFor(record.Level) {
+	if l.state.val == loggerStopped {
+		return
+	}
+	// propagated records are marked with negative call depth, they are
+	// already filtered by level of logger that created them and, while
+	// logger is being shut down, still accepted from its children
+	if calldepth >= 0 && (l.state.val == loggerDraining || !l.IsEnabledFor(record.Level)) {
 		return
 	}
 
-	var file string
-	var line int
-	var gotCaller bool
-	if l.includeFileAndLine && calldepth > 0 {
-		_, file, line, gotCaller = runtime.Caller(calldepth)
-		if !gotCaller {
-			file = "???"
-			line = -1
+	// file and line are only determined when record is created by caller,
+	// records propagated from children or created from slog records
+	// already carry them
+	if l.IncludeFileAndLine() && calldepth > 0 {
+		// program counter is kept in form returned by runtime.Callers, so
+		// that it can be passed to slog
+		var pcs [1]uintptr
+		if runtime.Callers(calldepth+1, pcs[:]) == 1 {
+			frame, _ := runtime.CallersFrames(pcs[:]).Next()
+			record.pc, record.File, record.Line = pcs[0], frame.File, frame.Line
+		} else {
+			record.File = "???"
+			record.Line = -1
 		}
 	}
+	if stackTraceLevel := l.StackTraceLevel(); stackTraceLevel != NOTSET && record.Level >= stackTraceLevel && calldepth > 0 {
+		record.Stack = captureStack(calldepth)
+	}
 
-	record.File = file
-	record.Line = line
-
+	atomic.AddUint64(&l.accepted, 1)
+	record.enqueued = time.Now()
+	record.retainFields()
+	if l.IsSynchronous() {
+		l.processSync(record)
+		return
+	}
 	atomic.AddInt32(&l.toProcess, 1)
-	l.rawRecords <- record
+	l.enqueue(record)
 }
 
 // Stop stops listening for new messages sent to this logger.
@@ -438,6 +777,10 @@ func (l *Logger) log(calldepth int, record Record) {
 func (l *Logger) stopAndWait(waitFunc func()) {
 	l.state.Lock()
 	defer l.state.Unlock()
+	// logger might have already been stopped by Shutdown
+	if l.state.val == loggerStopped {
+		return
+	}
 	// mark logger as stopped
 	l.state.val = loggerStopped
 	// stop processing of raw records
@@ -470,7 +813,8 @@ func (l *Logger) StopAndWait() {
 // silently be dropped. Return value indicates if all messages are processed (true)
 // or if provided timeout expired (false)
 func (l *Logger) StopAndWaitTimeout(t time.Duration) (finished bool) {
-	finished = false
+	// if logger is already stopped, there is nothing to wait for
+	finished = true
 	l.stopAndWait(func() {
 		finished = l.WaitTimeout(t)
 	})
@@ -484,36 +828,46 @@ func (l *Logger) IsRunning() bool {
 	return l.state.val == loggerRunning
 }
 
-// wait blocks until all messages on messages channel are processed.
-// Provided done channel will be closed when messages are processed to notify
-// interested parties that they can unblock.
-func (l *Logger) wait(done chan struct{}) {
+// wait blocks until all messages sent to this logger and all its children
+// are processed. Children are waited for first, since they might propagate
+// records to this logger.
+func (l *Logger) wait() {
 	runtime.Gosched()
-	l.relationship.RLock()
-	defer l.relationship.RUnlock()
+	children := l.children()
 	var wg sync.WaitGroup
-	wg.Add(len(l.relationship.children) + 1)
-	go func() {
-		l.notifyFinished <- done
-		wg.Done()
-	}()
-	for _, child := range l.relationship.children {
+	wg.Add(len(children))
+	for _, child := range children {
 		go func(l *Logger) {
-			chDone := make(chan struct{})
-			l.wait(chDone)
-			<-chDone
+			l.wait()
 			wg.Done()
 		}(child)
 	}
 	wg.Wait()
+	l.drain()
+	l.flush()
+}
+
+// flush flushes handler of this logger, if it buffers records, reporting
+// failure to error handler.
+func (l *Logger) flush() {
+	handler := l.Handler()
+	if err := flushHandler(handler); err != nil {
+		l.getErrorHandler()(Record{Time: time.Now().UTC(), Logger: l}, handler, err)
+	}
+}
+
+// drain blocks until all records queued to this logger are processed,
+// without waiting for its children.
+func (l *Logger) drain() {
+	done := make(chan struct{})
+	l.notifyWhenFinished(done)
+	<-done
 }
 
 // Wait block until all messages sent to logger are processed.
 // If timeout is needed, see WaitTimeout.
 func (l *Logger) Wait() {
-	done := make(chan struct{})
-	l.wait(done)
-	<-done
+	l.wait()
 }
 
 // WaitTimeout blocks until all messages send to logger are processed or max
@@ -523,7 +877,10 @@ func (l *Logger) Wait() {
 func (l *Logger) WaitTimeout(t time.Duration) (finished bool) {
 	done := make(chan struct{})
 	timeout := time.After(t)
-	l.wait(done)
+	go func() {
+		l.wait()
+		close(done)
+	}()
 	select {
 	case <-done:
 		return true
@@ -546,27 +903,11 @@ func (l *Logger) Log(calldepth int, level Level, message string, pairs ...interf
 	if !l.IsEnabledFor(level) {
 		return
 	}
-	var ctx = make(Ctx)
-
-	// make sure that number of items in data is even
-	pairsNo := len(pairs)
-	if pairsNo%2 != 0 && pairsNo > 0 {
-		// If there is no even number of items provided - add dummy values and
-		// indicate that there was a problem.
-		// However, if last provided unpaired item is instance of error,
-		// add it with key "err" without reporting problems. This is useful
-		// for logging stuff in format ligno.Error("Description", err)
-		last := pairs[pairsNo-1]
-		if _, ok := last.(error); ok {
-			pairs = append(pairs[:pairsNo-1], "err", last)
-		} else {
-			pairs = append(pairs, []interface{}{nil, "error", "missing key"}...)
-		}
-	}
-	for i := 0; i < len(pairs); i += 2 {
-		keyStr := fmt.Sprintf("%v", pairs[i])
-		ctx[keyStr] = pairs[i+1]
+	var ctx = make(Ctx, len(l.bound)+len(pairs)/2)
+	for k, v := range l.bound {
+		ctx[k] = v
 	}
+	addPairs(ctx, pairs)
 
 	r := Record{
 		Time:    time.Now().UTC(),
@@ -584,6 +925,9 @@ func (l *Logger) LogCtx(calldepth int, level Level, message string, data Ctx) {
 	if !l.IsEnabledFor(level) {
 		return
 	}
+	if len(l.bound) > 0 {
+		data = l.bound.merge(data)
+	}
 
 	r := Record{
 		Time:    time.Now().UTC(),
@@ -595,6 +939,50 @@ func (l *Logger) LogCtx(calldepth int, level Level, message string, data Ctx) {
 	l.log(calldepth+1, r)
 }
 
+// addPairs adds provided key-value pairs to context.
+// There should be even number of pairs. If there is not, dummy values are
+// added to indicate that there was a problem. However, if last provided
+// unpaired item is instance of error, it is added with key "err" without
+// reporting problems. This is useful for logging stuff in format
+// ligno.Error("Description", err)
+func addPairs(ctx Ctx, pairs []interface{}) {
+	pairsNo := len(pairs)
+	if pairsNo%2 != 0 {
+		last := pairs[pairsNo-1]
+		if _, ok := last.(error); ok {
+			ctx["err"] = last
+		} else {
+			ctx[fmt.Sprintf("%v", last)] = nil
+			ctx["error"] = "missing key"
+		}
+		pairsNo--
+	}
+	for i := 0; i < pairsNo; i += 2 {
+		keyStr := fmt.Sprintf("%v", pairs[i])
+		ctx[keyStr] = pairs[i+1]
+	}
+}
+
+// With returns view of this logger that adds provided key-value pairs to
+// every record logged through it. Pairs have same semantics as in Log method.
+// View shares queue, workers, handler and level with logger it is created
+// from and it is not registered as child in logger tree, so creating it is
+// cheap and it does not need to be stopped.
+func (l *Logger) With(pairs ...interface{}) *Logger {
+	ctx := make(Ctx, len(pairs)/2)
+	addPairs(ctx, pairs)
+	return l.WithCtx(ctx)
+}
+
+// WithCtx returns view of this logger that adds provided context to every
+// record logged through it. See With for details.
+func (l *Logger) WithCtx(ctx Ctx) *Logger {
+	return &Logger{
+		loggerCore: l.loggerCore,
+		bound:      l.bound.merge(ctx),
+	}
+}
+
 // Debug creates log record and queues it for processing with DEBUG level.
 // Additional parameters have same semantics as in Log method.
 func (l *Logger) Debug(message string, pairs ...interface{}) {
@@ -652,7 +1040,7 @@ func (l *Logger) CriticalCtx(message string, ctx Ctx) {
 
 // IsEnabledFor returns true if logger will process records with provided level.
 func (l *Logger) IsEnabledFor(level Level) bool {
-	return l.Level() <= level
+	return l.EffectiveLevel() <= level
 }
 
 // IsDebug returns true if logger will process messages in DEBUG level
@@ -206,3 +206,99 @@ func TestContext(t *testing.T) {
 	l2.Info("L2 event", "foo", "bar")
 	l1.Wait()
 }
+
+func TestWith(t *testing.T) {
+	var mu sync.Mutex
+	var records []Record
+	l := GetLoggerOptions("with."+randString(), LoggerOptions{
+		Context: Ctx{"service": "api"},
+		Handler: HandlerFunc(func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+			return nil
+		}),
+		PreventPropagation: true,
+	})
+	goroutines := runtime.NumGoroutine()
+	view := l.With("request_id", 1, "user", "john").WithCtx(Ctx{"user": "jane"})
+	if runtime.NumGoroutine() != goroutines {
+		t.Error("Expected view not to start new goroutines.")
+	}
+	if len(l.relationship.children) != 0 {
+		t.Error("Expected view not to be registered as child.")
+	}
+	view.Info("message", "request_id", 2)
+	view.InfoCtx("message ctx", Ctx{"extra": true})
+	l.Info("plain message")
+	l.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	first := records[0].Context
+	if first["service"] != "api" || first["request_id"] != 2 || first["user"] != "jane" {
+		t.Errorf("Unexpected context of record logged through view: %v", first)
+	}
+	second := records[1].Context
+	if second["request_id"] != 1 || second["extra"] != true {
+		t.Errorf("Unexpected context of record logged through view: %v", second)
+	}
+	if _, ok := records[2].Context["request_id"]; ok {
+		t.Errorf("Expected context bound to view not to be added to parent records: %v", records[2].Context)
+	}
+}
+
+func TestSetLevelInherited(t *testing.T) {
+	name := "levels." + randString()
+	parent := GetLoggerOptions(name, LoggerOptions{Level: WARNING, PreventPropagation: true})
+	child := GetLogger(name + ".child")
+	grandChild := GetLogger(name + ".child.grandchild")
+	if child.Level() != NOTSET || child.EffectiveLevel() != WARNING || grandChild.EffectiveLevel() != WARNING {
+		t.Fatalf("Expected level to be inherited, got %s and %s", child.EffectiveLevel(), grandChild.EffectiveLevel())
+	}
+	if child.IsInfo() || !child.IsError() {
+		t.Error("Expected child to process only records with inherited level.")
+	}
+
+	child.SetLevel(DEBUG)
+	if !grandChild.IsDebug() || parent.IsDebug() {
+		t.Error("Expected level change to apply to child subtree only.")
+	}
+
+	parent.SetLevelRecursive(ERROR)
+	for _, l := range []*Logger{parent, child, grandChild} {
+		if l.Level() != ERROR {
+			t.Errorf("Expected level of %s to be set recursively, got %s", l.FullName(), l.Level())
+		}
+	}
+
+	parent.SetLevelRecursive(NOTSET)
+	if child.EffectiveLevel() != rootLogger.EffectiveLevel() {
+		t.Error("Expected level to be inherited from root logger.")
+	}
+}
+
+func TestPropagatedRecordsNotFilteredByParentLevel(t *testing.T) {
+	handler := MemoryHandler(SimpleFormat())
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            handler,
+		Level:              INFO,
+		PreventPropagation: true,
+	})
+	defer parent.StopAndWait()
+	child := parent.SubLoggerOptions("child", LoggerOptions{Handler: NullHandler()})
+	defer child.StopAndWait()
+	child.SetLevel(DEBUG)
+
+	child.Debug("from child")
+	parent.Debug("from parent")
+	child.Wait()
+	parent.Wait()
+	messages := handler.Messages()
+	if len(messages) != 1 || !strings.Contains(messages[0], "from child") {
+		t.Errorf("Expected only record propagated from child, got %v", messages)
+	}
+}
@@ -0,0 +1,291 @@
+package ligno
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Framing defines how NetHandler separates records written to connection.
+type Framing uint8
+
+const (
+	// FramingNone writes formatted records as they are. It is suitable for
+	// datagram networks, where each record is sent in separate packet.
+	FramingNone Framing = iota
+	// FramingNewline terminates every record with single newline character.
+	FramingNewline
+	// FramingOctetCount prefixes every record with its length in bytes
+	// followed by space, as described in RFC 6587.
+	FramingOctetCount
+)
+
+// String returns name of framing.
+func (f Framing) String() string {
+	switch f {
+	case FramingNone:
+		return "None"
+	case FramingNewline:
+		return "Newline"
+	case FramingOctetCount:
+		return "OctetCount"
+	default:
+		return fmt.Sprintf("Framing(%d)", f)
+	}
+}
+
+// Default values for NetOptions.
+const (
+	defaultNetDialTimeout  = 5 * time.Second
+	defaultNetWriteTimeout = 5 * time.Second
+	defaultNetMinBackoff   = 100 * time.Millisecond
+	defaultNetMaxBackoff   = 30 * time.Second
+	defaultNetSpoolSize    = 1000
+)
+
+// ErrSpoolFull is reported by NetHandler when record is discarded because
+// in-memory spool for records written while disconnected is full.
+var ErrSpoolFull = errors.New("ligno: spool full, oldest record discarded")
+
+// NetOptions holds configuration for NetHandler.
+type NetOptions struct {
+	// Framing defines how records are separated. Default is FramingNone.
+	Framing Framing
+	// DialTimeout is max time to wait for connection to be established.
+	// If not set, 5s is used.
+	DialTimeout time.Duration
+	// WriteTimeout is max time to wait for single write to complete. If
+	// not set, 5s is used.
+	WriteTimeout time.Duration
+	// MinBackoff is time to wait before first reconnect attempt. Every
+	// subsequent failed attempt doubles it, up to MaxBackoff. If not set,
+	// 100ms is used.
+	MinBackoff time.Duration
+	// MaxBackoff is max time to wait between reconnect attempts. If not
+	// set, 30s is used.
+	MaxBackoff time.Duration
+	// SpoolSize is max number of records kept in memory while handler is
+	// disconnected. When exceeded, oldest record is discarded. If not set,
+	// 1000 is used.
+	SpoolSize int
+}
+
+// netHandler writes records to network connection, reconnecting when
+// connection breaks.
+type netHandler struct {
+	network   string
+	addr      string
+	formatter Formatter
+	options   NetOptions
+	mu        sync.Mutex
+	conn      net.Conn
+	// spool holds framed records written while disconnected, oldest first.
+	spool [][]byte
+	// backoff is time to wait after next failed connection attempt.
+	backoff time.Duration
+	// nextDial is time before which no connection attempt is made.
+	nextDial time.Time
+	// dialing is closed once connection attempt in progress is finished,
+	// nil if there is no such attempt.
+	dialing chan struct{}
+	// closed is set once handler is closed, to prevent new connections.
+	closed bool
+	// dial establishes connection, replaceable for testing purposes.
+	dial func(network, addr string) (net.Conn, error)
+	// now returns current time, replaceable for testing purposes.
+	now func() time.Time
+}
+
+// NetHandler creates handler that writes records to network address.
+// Network can be any network supported by net.Dial, e.g. "tcp", "udp" or
+// "unix". Connection is established when first record is handled. If
+// connection can not be established or breaks, records are kept in memory
+// and handler tries to reconnect with exponential backoff. Connection is
+// established in background, so that records are not blocked while it is
+// in progress, and errors of failed attempts are reported to error handler
+// of logger whose record triggered attempt. Spooled records are written
+// once connection is established again or when handler is closed.
+func NetHandler(network, addr string, formatter Formatter, options NetOptions) Handler {
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = defaultNetDialTimeout
+	}
+	if options.WriteTimeout <= 0 {
+		options.WriteTimeout = defaultNetWriteTimeout
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultNetMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = defaultNetMaxBackoff
+		if options.MaxBackoff < options.MinBackoff {
+			options.MaxBackoff = options.MinBackoff
+		}
+	}
+	if options.SpoolSize <= 0 {
+		options.SpoolSize = defaultNetSpoolSize
+	}
+	return &netHandler{
+		network:   network,
+		addr:      addr,
+		formatter: formatter,
+		options:   options,
+		backoff:   options.MinBackoff,
+		dial:      (&net.Dialer{Timeout: options.DialTimeout}).Dial,
+		now:       time.Now,
+	}
+}
+
+// Handle writes record to connection, or spools it if handler is
+// disconnected. Error is returned when write fails and when spooling record
+// caused oldest record to be discarded.
+func (nh *netHandler) Handle(record Record) error {
+	msg := nh.frame(nh.formatter.Format(record))
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+	if nh.conn == nil {
+		err := nh.push(msg)
+		nh.connect(record)
+		return err
+	}
+	var errs []error
+	// write records spooled while disconnected first, so that they do not
+	// get discarded to make room for this one
+	if err := nh.flush(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := nh.push(msg); err != nil {
+		errs = append(errs, err)
+	}
+	if nh.conn != nil {
+		if err := nh.flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close waits for connection attempt in progress, makes last attempt to
+// write spooled records and closes connection.
+func (nh *netHandler) Close() {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+	nh.closed = true
+	for nh.dialing != nil {
+		dialing := nh.dialing
+		nh.mu.Unlock()
+		<-dialing
+		nh.mu.Lock()
+	}
+	if len(nh.spool) > 0 && nh.conn == nil {
+		if conn, err := nh.dial(nh.network, nh.addr); err == nil {
+			nh.conn = conn
+		}
+	}
+	if nh.conn != nil {
+		nh.flush()
+	}
+	nh.spool = nil
+	nh.disconnect()
+}
+
+// frame applies configured framing to formatted record.
+func (nh *netHandler) frame(msg []byte) []byte {
+	switch nh.options.Framing {
+	case FramingNewline:
+		return append(bytes.TrimRight(msg, "\n"), '\n')
+	case FramingOctetCount:
+		msg = bytes.TrimRight(msg, "\n")
+		framed := strconv.AppendInt(make([]byte, 0, len(msg)+8), int64(len(msg)), 10)
+		framed = append(framed, ' ')
+		return append(framed, msg...)
+	default:
+		return msg
+	}
+}
+
+// push adds framed record to spool, discarding oldest record if spool is
+// full.
+func (nh *netHandler) push(msg []byte) error {
+	var err error
+	if len(nh.spool) >= nh.options.SpoolSize {
+		nh.spool[0] = nil
+		nh.spool = nh.spool[1:]
+		err = ErrSpoolFull
+	}
+	nh.spool = append(nh.spool, msg)
+	return err
+}
+
+// connect starts connection attempt in background, unless handler is
+// connected, attempt is already in progress or backoff period has not
+// expired yet. Once connection is established, spooled records are written
+// to it. Errors are reported to error handler of logger of provided record.
+// It must be called with lock held.
+func (nh *netHandler) connect(record Record) {
+	if nh.conn != nil || nh.dialing != nil || nh.closed || nh.now().Before(nh.nextDial) {
+		return
+	}
+	dialing := make(chan struct{})
+	nh.dialing = dialing
+	record = record.Detach()
+	go func() {
+		conn, err := nh.dial(nh.network, nh.addr)
+		nh.mu.Lock()
+		switch {
+		case err != nil:
+			nh.scheduleReconnect(nh.now())
+		case nh.closed:
+			// Close is waiting for this attempt and will write spool
+			nh.conn = conn
+		default:
+			nh.conn = conn
+			nh.backoff = nh.options.MinBackoff
+			err = nh.flush()
+		}
+		nh.dialing = nil
+		nh.mu.Unlock()
+		close(dialing)
+		if err != nil {
+			record.Logger.getErrorHandler()(record, nh, err)
+		}
+	}()
+}
+
+// scheduleReconnect sets time of next connection attempt and increases
+// backoff for attempt after it.
+func (nh *netHandler) scheduleReconnect(now time.Time) {
+	nh.nextDial = now.Add(nh.backoff)
+	nh.backoff *= 2
+	if nh.backoff > nh.options.MaxBackoff {
+		nh.backoff = nh.options.MaxBackoff
+	}
+}
+
+// flush writes spooled records to connection. If write fails, connection
+// is closed and unwritten records stay in spool.
+func (nh *netHandler) flush() error {
+	for len(nh.spool) > 0 {
+		nh.conn.SetWriteDeadline(nh.now().Add(nh.options.WriteTimeout))
+		if _, err := nh.conn.Write(nh.spool[0]); err != nil {
+			nh.disconnect()
+			nh.scheduleReconnect(nh.now())
+			return err
+		}
+		nh.spool[0] = nil
+		nh.spool = nh.spool[1:]
+	}
+	return nil
+}
+
+// disconnect closes connection if it is opened.
+func (nh *netHandler) disconnect() {
+	if nh.conn == nil {
+		return
+	}
+	nh.conn.Close()
+	nh.conn = nil
+}
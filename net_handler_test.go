@@ -0,0 +1,140 @@
+package ligno
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNetHandlerNewlineFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			received <- scanner.Text()
+		}
+	}()
+
+	handler := NetHandler("tcp", listener.Addr().String(), FormatterFunc(func(record Record) []byte {
+		return []byte(record.Message)
+	}), NetOptions{Framing: FramingNewline})
+	for _, msg := range []string{"first", "second"} {
+		if err := handler.Handle(Record{Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler.(HandlerCloser).Close()
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case got := <-received:
+			if got != expected {
+				t.Errorf("Expected %q, got %q", expected, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %q", expected)
+		}
+	}
+}
+
+func TestNetHandlerSpoolAndReconnect(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	dialErrs := make(chan error, 10)
+	l := createLogger("net", LoggerOptions{
+		Handler: NullHandler(),
+		ErrorHandler: func(record Record, handler Handler, err error) {
+			dialErrs <- err
+		},
+	})
+	defer l.StopAndWait()
+	now := time.Now()
+	var dials int32
+	nh := NetHandler("tcp", "unused", FormatterFunc(func(record Record) []byte {
+		return []byte(record.Message)
+	}), NetOptions{Framing: FramingOctetCount, SpoolSize: 2, MinBackoff: time.Second}).(*netHandler)
+	nh.now = func() time.Time { return now }
+	nh.dial = func(network, addr string) (net.Conn, error) {
+		if atomic.AddInt32(&dials, 1) == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return client, nil
+	}
+
+	if err := nh.Handle(Record{Message: "a", Logger: l}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-dialErrs:
+	case <-time.After(time.Second):
+		t.Fatal("Expected dial error to be reported.")
+	}
+	nh.Handle(Record{Message: "bb", Logger: l})
+	if err := nh.Handle(Record{Message: "ccc", Logger: l}); !errors.Is(err, ErrSpoolFull) {
+		t.Errorf("Expected spool full error, got %v", err)
+	}
+	if dials := atomic.LoadInt32(&dials); dials != 1 {
+		t.Errorf("Expected no reconnect before backoff expires, got %d dials", dials)
+	}
+
+	nh.mu.Lock()
+	now = now.Add(2 * time.Second)
+	nh.mu.Unlock()
+	received := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(server)
+		received <- data
+	}()
+	if err := nh.Handle(Record{Message: "dddd", Logger: l}); !errors.Is(err, ErrSpoolFull) {
+		t.Errorf("Expected spool full error, got %v", err)
+	}
+	nh.Close()
+	if got, expected := string(<-received), "3 ccc4 dddd"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	select {
+	case err := <-dialErrs:
+		t.Errorf("Unexpected error: %v", err)
+	default:
+	}
+}
+
+func TestNetHandlerDialDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	nh := NetHandler("tcp", "unused", SimpleFormat(), NetOptions{}).(*netHandler)
+	nh.dial = func(network, addr string) (net.Conn, error) {
+		<-release
+		return nil, errors.New("connection refused")
+	}
+	l := createLogger("net", LoggerOptions{
+		Handler:      NullHandler(),
+		ErrorHandler: func(Record, Handler, error) {},
+	})
+	defer l.StopAndWait()
+	handled := make(chan struct{})
+	go func() {
+		nh.Handle(Record{Message: "first", Logger: l})
+		nh.Handle(Record{Message: "second", Logger: l})
+		close(handled)
+	}()
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Error("Expected records to be handled while connection is being established.")
+	}
+	close(release)
+	nh.Close()
+}
@@ -0,0 +1,105 @@
+package ligno
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what logger does with new record when its buffer
+// for records is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock blocks caller until there is room for new record in
+	// buffer. This is default policy.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards record that is being logged if buffer is full.
+	OverflowDropNewest
+	// OverflowDropOldest discards oldest record in buffer to make room for
+	// record that is being logged.
+	OverflowDropOldest
+	// OverflowBlockWithTimeout blocks caller until there is room in buffer or
+	// until timeout set in LoggerOptions.OverflowTimeout expires, in which
+	// case record that is being logged is discarded.
+	OverflowBlockWithTimeout
+)
+
+// String returns name of overflow policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowDropNewest:
+		return "DropNewest"
+	case OverflowDropOldest:
+		return "DropOldest"
+	case OverflowBlockWithTimeout:
+		return "BlockWithTimeout"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", p)
+	}
+}
+
+// defaultOverflowTimeout is used with OverflowBlockWithTimeout policy if
+// timeout is not provided in logger options.
+const defaultOverflowTimeout = 100 * time.Millisecond
+
+// enqueue sends record to raw records channel respecting overflow policy
+// of logger. Caller is responsible for increasing number of records to
+// process before calling enqueue, enqueue takes care of decreasing it if
+// record ends up being dropped.
+func (l *Logger) enqueue(record Record) {
+	switch l.overflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case l.rawRecords <- record:
+		default:
+			l.drop(record)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case l.rawRecords <- record:
+				return
+			default:
+			}
+			// make room by discarding oldest record, but do not block if
+			// processing goroutine took it in the meantime
+			select {
+			case oldest := <-l.rawRecords:
+				l.drop(oldest)
+			default:
+			}
+		}
+	case OverflowBlockWithTimeout:
+		// try fast path first to avoid creating timer if buffer has space
+		select {
+		case l.rawRecords <- record:
+			return
+		default:
+		}
+		timer := time.NewTimer(l.overflowTimeout)
+		defer timer.Stop()
+		select {
+		case l.rawRecords <- record:
+		case <-timer.C:
+			l.drop(record)
+		}
+	default:
+		l.rawRecords <- record
+	}
+}
+
+// drop marks one record as dropped.
+func (l *Logger) drop(record Record) {
+	atomic.AddUint64(&l.dropped, 1)
+	record.releaseFields()
+	l.recordDone()
+}
+
+// Dropped returns number of records that were discarded by this logger
+// because its buffer was full.
+func (l *Logger) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
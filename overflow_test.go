@@ -0,0 +1,69 @@
+package ligno
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingHandler returns handler that blocks until release channel is
+// closed and counter of handled records.
+func blockingHandler(release chan struct{}) (Handler, *int32) {
+	var handled int32
+	return HandlerFunc(func(record Record) error {
+		<-release
+		atomic.AddInt32(&handled, 1)
+		return nil
+	}), &handled
+}
+
+func TestOverflowPolicyDrop(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowBlockWithTimeout} {
+		release := make(chan struct{})
+		handler, handled := blockingHandler(release)
+		l := GetLoggerOptions("overflow."+policy.String()+randString(), LoggerOptions{
+			Handler:            handler,
+			BufferSize:         1,
+			PreventPropagation: true,
+			OverflowPolicy:     policy,
+			OverflowTimeout:    time.Millisecond,
+		})
+		const total = 100
+		for i := 0; i < total; i++ {
+			l.Info("message")
+		}
+		// at most one record can be in handler, one in each buffer and one
+		// held by goroutine that moves records between buffers
+		if l.Dropped() < total-4 {
+			t.Errorf("%s: expected at least %d dropped records, got %d", policy, total-4, l.Dropped())
+		}
+		close(release)
+		if !l.WaitTimeout(time.Second) {
+			t.Fatalf("%s: logger did not finish processing records", policy)
+		}
+		if got := uint64(atomic.LoadInt32(handled)) + l.Dropped(); got != total {
+			t.Errorf("%s: expected %d handled and dropped records, got %d", policy, total, got)
+		}
+	}
+}
+
+func TestOverflowPolicyBlock(t *testing.T) {
+	release := make(chan struct{})
+	handler, handled := blockingHandler(release)
+	l := GetLoggerOptions("overflow.block"+randString(), LoggerOptions{
+		Handler:            handler,
+		BufferSize:         1,
+		PreventPropagation: true,
+	})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	for i := 0; i < 10; i++ {
+		l.Info("message")
+	}
+	l.Wait()
+	if l.Dropped() != 0 || atomic.LoadInt32(handled) != 10 {
+		t.Errorf("expected all records to be handled, got %d handled, %d dropped", atomic.LoadInt32(handled), l.Dropped())
+	}
+}
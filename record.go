@@ -1,6 +1,9 @@
 package ligno
 
-import "time"
+import (
+	"runtime"
+	"time"
+)
 
 // Ctx is additional context for log record.
 type Ctx map[string]interface{}
@@ -19,11 +22,105 @@ func (ctx Ctx) merge(other Ctx) (merged Ctx) {
 
 // Record holds information about one log message.
 type Record struct {
-	Time    time.Time `json:"time"`
-	Level   Level     `json:"level"`
-	Message string    `json:"message"`
-	Context Ctx       `json:"context"`
-	Logger  *Logger   `json:"-"`
-	File    string    `json:"file"`
-	Line    int       `json:"line"`
+	Time    time.Time    `json:"time"`
+	Level   Level        `json:"level"`
+	Message string       `json:"message"`
+	Context Ctx          `json:"context"`
+	Logger  *Logger      `json:"-"`
+	File    string       `json:"file"`
+	Line    int          `json:"line"`
+	Stack   []StackFrame `json:"stack,omitempty"`
+	// Fields holds typed fields of record logged with LogF and similar
+	// methods. Unlike Context, fields are pooled and reused once record is
+	// processed, so handlers must not keep them after Handle returns, unless
+	// record is copied with Detach.
+	Fields []Field `json:"-"`
+	// fieldBuf is pooled buffer that holds fields.
+	fieldBuf *fieldBuffer
+	// enqueued is time when record was queued to logger that is currently
+	// processing it, used for measuring processing latency.
+	enqueued time.Time
+	// pc is program counter of place where record was logged, if file and
+	// line were determined, used when record is forwarded to slog.
+	pc uintptr
+}
+
+// Value returns value with provided key from record context or fields.
+// Context has precedence over fields.
+func (r Record) Value(key string) (interface{}, bool) {
+	if value, ok := r.Context[key]; ok {
+		return value, true
+	}
+	for _, field := range r.Fields {
+		if field.Key == key {
+			return field.Value(), true
+		}
+	}
+	return nil, false
+}
+
+// StackFrame holds information about single frame of stack trace.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// maxStackDepth is max number of frames captured in stack trace.
+const maxStackDepth = 1024
+
+// captureStack returns stack trace of current goroutine. Skip is number of
+// frames to skip, with 0 identifying caller of captureStack.
+func captureStack(skip int) []StackFrame {
+	// skip captureStack itself
+	return stackFrames(callers(skip + 1))
+}
+
+// captureStackFrom returns stack trace of current goroutine starting with
+// frame of provided program counter, as returned by runtime.Callers. If
+// frame is not found, stack trace starts with caller of captureStackFrom.
+func captureStackFrom(pc uintptr) []StackFrame {
+	pcs := callers(1)
+	for i := range pcs {
+		if pcs[i] == pc {
+			pcs = pcs[i:]
+			break
+		}
+	}
+	return stackFrames(pcs)
+}
+
+// callers returns program counters of current goroutine stack. Skip is
+// number of frames to skip, with 0 identifying caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 32)
+	for {
+		// skip runtime.Callers and callers itself
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) || len(pcs) >= maxStackDepth {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+}
+
+// stackFrames resolves program counters to stack frames.
+func stackFrames(pcs []uintptr) []StackFrame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	stack := make([]StackFrame, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, StackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
 }
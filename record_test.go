@@ -2,6 +2,9 @@ package ligno
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -51,3 +54,73 @@ func TestSerializeRecordToJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestStackTraceCaptured(t *testing.T) {
+	var mu sync.Mutex
+	var records []Record
+	l := GetLoggerOptions("stack."+randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+			return nil
+		}),
+		StackTraceLevel:    ERROR,
+		PreventPropagation: true,
+	})
+	l.Warning("without stack")
+	l.Error("with stack")
+	l.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Stack != nil {
+		t.Error("Expected no stack trace for record below stack trace level.")
+	}
+	stack := records[1].Stack
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestStackTraceCaptured") {
+		t.Fatalf("Expected stack trace starting with test function, got %+v", stack)
+	}
+
+	terminal := string(ThemedTerminalFormat(NoColorTheme).Format(records[1]))
+	if !strings.Contains(terminal, "\n\t"+stack[0].Function+"\n\t\t"+stack[0].File) {
+		t.Errorf("Expected multi-line stack trace in terminal output, got %q", terminal)
+	}
+	var marshaled struct {
+		Stack []StackFrame `json:"stack"`
+	}
+	if err := json.Unmarshal(JSONFormat(false).Format(records[1]), &marshaled); err != nil {
+		t.Fatal(err)
+	}
+	if len(marshaled.Stack) != len(stack) || marshaled.Stack[0] != stack[0] {
+		t.Errorf("Expected stack frames in JSON output, got %+v", marshaled.Stack)
+	}
+}
+
+func TestJSONFormatMatchesEncodingJSON(t *testing.T) {
+	record := Record{
+		Time:    time.Date(2016, 1, 2, 3, 4, 5, 6, time.UTC),
+		Level:   WARNING,
+		Message: "quote \" <tag> & \u2028 \x01 \xff",
+		Context: Ctx{"b": 1, "a": "line\nbreak", "err": errors.New("boom")},
+		File:    "file.go",
+		Line:    42,
+		Stack:   []StackFrame{{Function: "main", File: "main.go", Line: 1}},
+	}
+	for _, pretty := range []bool{false, true} {
+		expected := record
+		expected.Context = Ctx{"b": "1", "a": "line\nbreak", "err": jsonErrorDetails(DescribeError(record.Context["err"].(error)))}
+		var marshaled []byte
+		if pretty {
+			marshaled, _ = json.MarshalIndent(expected, "", "    ")
+		} else {
+			marshaled, _ = json.Marshal(expected)
+		}
+		if formatted := string(JSONFormat(pretty).Format(record)); formatted != string(marshaled)+"\n" {
+			t.Errorf("Expected %s, got %s", marshaled, formatted)
+		}
+	}
+}
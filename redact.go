@@ -0,0 +1,348 @@
+package ligno
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactStrategy defines how redacted values are masked.
+type RedactStrategy uint8
+
+const (
+	// RedactReplace replaces value with replacement string. This is default
+	// strategy.
+	RedactReplace RedactStrategy = iota
+	// RedactHash replaces value with HMAC-SHA256 of value, so that equal
+	// values can still be correlated without revealing them.
+	RedactHash
+	// RedactTruncate keeps only beginning of value.
+	RedactTruncate
+)
+
+// Patterns of commonly logged sensitive values, for use in
+// RedactOptions.ValuePatterns.
+var (
+	// CreditCardPattern matches credit card numbers, optionally separated
+	// with spaces or dashes.
+	CreditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// JWTPattern matches JSON web tokens.
+	JWTPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// EmailPattern matches email addresses.
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// Default values for RedactOptions.
+const (
+	defaultRedactReplacement    = "[REDACTED]"
+	defaultRedactTruncateLength = 4
+	// maxRedactDepth is max depth of nested values that are inspected.
+	maxRedactDepth = 10
+)
+
+// RedactOptions holds configuration for Redactor.
+type RedactOptions struct {
+	// Keys holds names of context keys whose values are redacted. Names
+	// are matched case insensitively and may contain glob patterns, as
+	// defined by path.Match (e.g. "*token*"). Keys are matched at any
+	// depth of nested maps and structs.
+	Keys []string
+	// KeyPatterns holds regular expressions for matching context keys
+	// whose values are redacted.
+	KeyPatterns []*regexp.Regexp
+	// ValuePatterns holds regular expressions for matching sensitive parts
+	// of string values, regardless of key, e.g. CreditCardPattern. Errors
+	// and fmt.Stringer values are matched against their text and replaced
+	// by redacted text if anything in it matches. Only matched part of
+	// value is redacted.
+	ValuePatterns []*regexp.Regexp
+	// Strategy defines how values are masked.
+	Strategy RedactStrategy
+	// Replacement is string used by RedactReplace strategy. If not set,
+	// "[REDACTED]" is used.
+	Replacement string
+	// HashKey is key used for HMAC by RedactHash strategy.
+	HashKey []byte
+	// TruncateLength is number of characters kept by RedactTruncate
+	// strategy. If not set, 4 is used.
+	TruncateLength int
+}
+
+// Redactor masks sensitive values in context of records.
+type Redactor struct {
+	options RedactOptions
+	// exact holds lower cased keys that do not contain glob patterns.
+	exact map[string]bool
+	// globs holds lower cased keys that contain glob patterns.
+	globs []string
+}
+
+// NewRedactor creates redactor with provided options.
+func NewRedactor(options RedactOptions) *Redactor {
+	if options.Replacement == "" {
+		options.Replacement = defaultRedactReplacement
+	}
+	if options.TruncateLength <= 0 {
+		options.TruncateLength = defaultRedactTruncateLength
+	}
+	r := &Redactor{
+		options: options,
+		exact:   make(map[string]bool),
+	}
+	for _, key := range options.Keys {
+		key = strings.ToLower(key)
+		if strings.ContainsAny(key, `*?[\`) {
+			r.globs = append(r.globs, key)
+		} else {
+			r.exact[key] = true
+		}
+	}
+	return r
+}
+
+// Process returns record with sensitive context and field values masked.
+// Context and fields of provided record are not modified. It can be used
+// as Processor in LoggerOptions.
+func (r *Redactor) Process(record Record) Record {
+	if len(record.Context) > 0 {
+		ctx := make(Ctx, len(record.Context))
+		for k, v := range record.Context {
+			ctx[k] = r.redactPair(k, v, 0)
+		}
+		record.Context = ctx
+	}
+	if len(record.Fields) > 0 {
+		fields := make([]Field, len(record.Fields))
+		for i, field := range record.Fields {
+			fields[i] = r.redactField(field)
+		}
+		record.Fields = fields
+	}
+	return record
+}
+
+// redactField returns field redacted according to its key and value.
+func (r *Redactor) redactField(field Field) Field {
+	switch {
+	case r.sensitiveKey(field.Key):
+		return String(field.Key, r.mask(string(field.AppendText(nil))))
+	case field.Type == StringType:
+		field.String = r.redactString(field.String)
+	case field.Type == ErrorType && field.Interface != nil:
+		if redacted, changed := r.redactText(field.Interface); changed {
+			return String(field.Key, redacted)
+		}
+	case field.Type == ObjectType && field.Interface != nil:
+		field.Interface = r.redactPair(field.Key, field.Interface, 0)
+	}
+	return field
+}
+
+// RedactingHandler creates handler that masks sensitive context values
+// with provided redactor before passing records to handler.
+func RedactingHandler(redactor *Redactor, handler Handler) Handler {
+	return &redactingHandler{
+		redactor: redactor,
+		handler:  handler,
+	}
+}
+
+// redactingHandler passes redacted records to handler.
+type redactingHandler struct {
+	redactor *Redactor
+	handler  Handler
+}
+
+// Handle passes redacted record to internal handler.
+func (rh *redactingHandler) Handle(record Record) error {
+	return rh.handler.Handle(rh.redactor.Process(record))
+}
+
+// Close closes internal handler if it implements HandlerCloser interface.
+func (rh *redactingHandler) Close() {
+	if handlerCloser, ok := rh.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// Flush flushes internal handler if it implements HandlerFlusher interface.
+func (rh *redactingHandler) Flush() error {
+	return flushHandler(rh.handler)
+}
+
+// sensitiveKey returns true if values with provided key should be redacted.
+func (r *Redactor) sensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	if r.exact[lower] {
+		return true
+	}
+	for _, glob := range r.globs {
+		if matched, _ := path.Match(glob, lower); matched {
+			return true
+		}
+	}
+	for _, pattern := range r.options.KeyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPair returns value redacted according to its key and content.
+func (r *Redactor) redactPair(key string, value interface{}, depth int) interface{} {
+	if value == nil {
+		return nil
+	}
+	if r.sensitiveKey(key) {
+		return r.mask(logfmtValue(value))
+	}
+	redacted, _ := r.redactValue(reflect.ValueOf(value), depth)
+	return redacted
+}
+
+// redactValue returns value with sensitive nested values and parts of
+// strings redacted. Maps, slices and structs are returned as generic maps
+// and slices if anything in them was redacted, otherwise original value is
+// returned. Second return value indicates if value was changed.
+func (r *Redactor) redactValue(v reflect.Value, depth int) (interface{}, bool) {
+	original := v.Interface()
+	if depth >= maxRedactDepth {
+		return original, false
+	}
+	if s, ok := original.(string); ok {
+		redacted := r.redactString(s)
+		return redacted, redacted != s
+	}
+	if redacted, changed := r.redactText(original); changed {
+		return redacted, true
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return original, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		redacted := r.redactString(v.String())
+		return redacted, redacted != v.String()
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return original, false
+		}
+		changed := false
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			m[key], changed = r.redactNested(key, iter.Value(), depth, changed)
+		}
+		if changed {
+			return m, true
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return original, false
+		}
+		changed := false
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			var elemChanged bool
+			s[i], elemChanged = r.redactValue(v.Index(i), depth+1)
+			changed = changed || elemChanged
+		}
+		if changed {
+			return s, true
+		}
+	case reflect.Struct:
+		changed := false
+		t := v.Type()
+		m := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := field.Name
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			m[name], changed = r.redactNested(name, v.Field(i), depth, changed)
+		}
+		if changed {
+			return m, true
+		}
+	}
+	return original, false
+}
+
+// redactNested redacts value of nested key and returns it together with
+// flag indicating if this or any previous nested value was changed.
+func (r *Redactor) redactNested(key string, v reflect.Value, depth int, changed bool) (interface{}, bool) {
+	if r.sensitiveKey(key) {
+		if v.Kind() == reflect.Interface && v.IsNil() {
+			return nil, changed
+		}
+		return r.mask(logfmtValue(v.Interface())), true
+	}
+	redacted, nestedChanged := r.redactValue(v, depth+1)
+	return redacted, changed || nestedChanged
+}
+
+// redactText renders errors and fmt.Stringer values as strings and masks
+// parts of them that match value patterns. Second return value indicates if
+// anything was masked, if not, value should be kept as it is, so that
+// formatters can render it in their own way (e.g. error chains).
+func (r *Redactor) redactText(value interface{}) (string, bool) {
+	if len(r.options.ValuePatterns) == 0 {
+		return "", false
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return "", false
+	}
+	var s string
+	switch value := value.(type) {
+	case error:
+		s = value.Error()
+	case fmt.Stringer:
+		s = value.String()
+	default:
+		return "", false
+	}
+	redacted := r.redactString(s)
+	return redacted, redacted != s
+}
+
+// redactString masks parts of string that match value patterns.
+func (r *Redactor) redactString(s string) string {
+	for _, pattern := range r.options.ValuePatterns {
+		s = pattern.ReplaceAllStringFunc(s, r.mask)
+	}
+	return s
+}
+
+// mask masks provided value according to redaction strategy.
+func (r *Redactor) mask(value string) string {
+	switch r.options.Strategy {
+	case RedactHash:
+		mac := hmac.New(sha256.New, r.options.HashKey)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))
+	case RedactTruncate:
+		if utf8.RuneCountInString(value) <= r.options.TruncateLength {
+			return strings.Repeat("*", utf8.RuneCountInString(value))
+		}
+		return string([]rune(value)[:r.options.TruncateLength]) + "***"
+	default:
+		return r.options.Replacement
+	}
+}
@@ -0,0 +1,116 @@
+package ligno
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactorKeysAndNested(t *testing.T) {
+	type credentials struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	redactor := NewRedactor(RedactOptions{
+		Keys:        []string{"password", "*token*"},
+		KeyPatterns: []*regexp.Regexp{regexp.MustCompile(`^secret_`)},
+	})
+	original := Ctx{
+		"password":     "hunter2",
+		"Access_Token": "abc",
+		"secret_key":   42,
+		"user":         "bob",
+		"nested":       map[string]interface{}{"password": "x", "ok": 1},
+		"creds":        credentials{User: "bob", Password: "y"},
+	}
+	record := redactor.Process(Record{Context: original})
+	for _, key := range []string{"password", "Access_Token", "secret_key"} {
+		if record.Context[key] != defaultRedactReplacement {
+			t.Errorf("Expected %s to be redacted, got %v", key, record.Context[key])
+		}
+	}
+	if record.Context["user"] != "bob" {
+		t.Errorf("Expected user to be kept, got %v", record.Context["user"])
+	}
+	nested := record.Context["nested"].(map[string]interface{})
+	if nested["password"] != defaultRedactReplacement || nested["ok"] != 1 {
+		t.Errorf("Unexpected nested map: %v", nested)
+	}
+	creds := record.Context["creds"].(map[string]interface{})
+	if creds["password"] != defaultRedactReplacement || creds["user"] != "bob" {
+		t.Errorf("Unexpected nested struct: %v", creds)
+	}
+	if original["password"] != "hunter2" {
+		t.Error("Expected original context not to be modified.")
+	}
+}
+
+func TestRedactorValuePatternsAndStrategies(t *testing.T) {
+	record := Record{Context: Ctx{"msg": "card 4111 1111 1111 1111 of bob@example.com"}}
+
+	hashed := NewRedactor(RedactOptions{
+		ValuePatterns: []*regexp.Regexp{CreditCardPattern, EmailPattern},
+		Strategy:      RedactHash,
+		HashKey:       []byte("key"),
+	}).Process(record).Context["msg"].(string)
+	if strings.Contains(hashed, "4111") || strings.Contains(hashed, "bob@") || strings.Count(hashed, "hmac:") != 2 {
+		t.Errorf("Expected card and email to be hashed, got %s", hashed)
+	}
+
+	truncated := NewRedactor(RedactOptions{
+		ValuePatterns: []*regexp.Regexp{EmailPattern},
+		Strategy:      RedactTruncate,
+	}).Process(record).Context["msg"].(string)
+	if !strings.HasSuffix(truncated, " of bob@***") {
+		t.Errorf("Expected email to be truncated, got %s", truncated)
+	}
+}
+
+func TestRedactorLoggerProcessor(t *testing.T) {
+	handler := MemoryHandler(JSONFormat(false))
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            handler,
+		PreventPropagation: true,
+		Processors:         []Processor{NewRedactor(RedactOptions{Keys: []string{"password"}}).Process},
+	})
+	defer l.StopAndWait()
+	l.Info("login", "password", "hunter2")
+	l.Wait()
+	if messages := handler.Messages(); len(messages) != 1 || strings.Contains(messages[0], "hunter2") {
+		t.Errorf("Expected password to be redacted, got %v", messages)
+	}
+}
+
+type accountNumber string
+
+func (a accountNumber) String() string {
+	return "account 4111 1111 1111 1111"
+}
+
+func TestRedactorValuePatternsErrorsAndStringers(t *testing.T) {
+	redactor := NewRedactor(RedactOptions{ValuePatterns: []*regexp.Regexp{CreditCardPattern}})
+	cardErr := errors.New("charging 4111 1111 1111 1111 failed")
+	plainErr := errors.New("timeout")
+	record := redactor.Process(Record{
+		Context: Ctx{"error": cardErr, "other": plainErr, "account": accountNumber("x")},
+		Fields:  []Field{Err(cardErr), Object("account", accountNumber("x"))},
+	})
+	for key, expected := range map[string]string{
+		"error":   "charging [REDACTED] failed",
+		"account": "account [REDACTED]",
+	} {
+		if record.Context[key] != expected {
+			t.Errorf("Expected %s to be %q, got %v", key, expected, record.Context[key])
+		}
+	}
+	if record.Context["other"] != plainErr {
+		t.Errorf("Expected error without sensitive data to be kept, got %v", record.Context["other"])
+	}
+	if f := record.Fields[0]; f.Key != "err" || f.Value() != "charging [REDACTED] failed" {
+		t.Errorf("Expected error field to be redacted, got %+v", f)
+	}
+	if f := record.Fields[1]; f.Value() != "account [REDACTED]" {
+		t.Errorf("Expected stringer field to be redacted, got %+v", f)
+	}
+}
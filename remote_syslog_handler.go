@@ -0,0 +1,315 @@
+package ligno
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogFormat defines syslog protocol used by RemoteSyslogHandler.
+type SyslogFormat uint8
+
+const (
+	// SyslogRFC5424 formats messages according to RFC 5424. Record context
+	// is sent as structured data. This is default format.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 formats messages according to RFC 3164 (BSD syslog).
+	SyslogRFC3164
+)
+
+// String returns name of syslog format.
+func (f SyslogFormat) String() string {
+	switch f {
+	case SyslogRFC5424:
+		return "RFC5424"
+	case SyslogRFC3164:
+		return "RFC3164"
+	default:
+		return fmt.Sprintf("SyslogFormat(%d)", f)
+	}
+}
+
+// SyslogFacility is syslog facility, as defined in RFC 5424.
+type SyslogFacility uint8
+
+// Syslog facilities.
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthpriv
+	FacilityFtp
+	_ // NTP subsystem
+	_ // log audit
+	_ // log alert
+	_ // clock daemon
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogSeverity is syslog severity, as defined in RFC 5424.
+type SyslogSeverity uint8
+
+// Syslog severities.
+const (
+	SeverityEmergency SyslogSeverity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// DefaultSyslogSeverities maps builtin levels to syslog severities.
+var DefaultSyslogSeverities = map[Level]SyslogSeverity{
+	DEBUG:    SeverityDebug,
+	INFO:     SeverityInfo,
+	WARNING:  SeverityWarning,
+	ERROR:    SeverityError,
+	CRITICAL: SeverityCritical,
+}
+
+// defaultSyslogSDID is structured data ID used for record context if none
+// is provided. 32473 is private enterprise number reserved for
+// documentation.
+const defaultSyslogSDID = "ctx@32473"
+
+// SyslogOptions holds configuration for RemoteSyslogHandler.
+type SyslogOptions struct {
+	// Format is syslog protocol used for messages.
+	Format SyslogFormat
+	// Facility is syslog facility of messages. Since kernel facility is
+	// not meant to be used by user processes, zero value means FacilityUser.
+	Facility SyslogFacility
+	// Hostname is name of host sent with messages. If not set, result of
+	// os.Hostname is used.
+	Hostname string
+	// AppName is name of application sent with messages (tag in RFC 3164).
+	// If not set, name of executable is used.
+	AppName string
+	// Severities maps levels to syslog severities. Record gets severity of
+	// highest mapped level that is not above record's level, which means
+	// that custom levels do not have to be mapped explicitly. Records with
+	// level below all mapped levels get SeverityInfo. If not set,
+	// DefaultSyslogSeverities is used.
+	Severities map[Level]SyslogSeverity
+	// SDID is structured data ID of element holding record context in
+	// RFC 5424 messages. If not set, "ctx@32473" is used.
+	SDID string
+	// Net holds options for network connection. If framing is not set
+	// and network is stream oriented (tcp or unix), octet counting is used
+	// for RFC 5424 and newline for RFC 3164 messages.
+	Net NetOptions
+}
+
+// RemoteSyslogHandler creates handler that sends records to syslog server
+// listening on provided address. Network can be "udp", "tcp", "unix" or
+// "unixgram" (or any other network supported by NetHandler), for example
+// ("unixgram", "/dev/log") for local syslog daemon. Formatter is used for
+// message part; if nil, only record message is sent. Connection handling
+// is same as in NetHandler, so handler does not fail if syslog server is
+// not available.
+func RemoteSyslogHandler(network, addr string, formatter Formatter, options SyslogOptions) Handler {
+	if options.Facility == FacilityKern {
+		options.Facility = FacilityUser
+	}
+	if options.Hostname == "" {
+		options.Hostname, _ = os.Hostname()
+	}
+	if options.AppName == "" {
+		options.AppName = filepath.Base(os.Args[0])
+	}
+	if options.Severities == nil {
+		options.Severities = DefaultSyslogSeverities
+	}
+	if options.SDID == "" {
+		options.SDID = defaultSyslogSDID
+	}
+	if formatter == nil {
+		formatter = FormatterFunc(func(record Record) []byte {
+			return []byte(record.Message)
+		})
+	}
+	if options.Net.Framing == FramingNone && isStreamNetwork(network) {
+		if options.Format == SyslogRFC3164 {
+			options.Net.Framing = FramingNewline
+		} else {
+			options.Net.Framing = FramingOctetCount
+		}
+	}
+	sf := &syslogFormatter{
+		options:   options,
+		formatter: formatter,
+		levels:    sortedSyslogLevels(options.Severities),
+		pid:       strconv.Itoa(os.Getpid()),
+	}
+	return NetHandler(network, addr, sf, options.Net)
+}
+
+// isStreamNetwork returns true if messages sent over provided network need
+// to be framed.
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// sortedSyslogLevels returns levels from severity mapping in descending order.
+func sortedSyslogLevels(severities map[Level]SyslogSeverity) []Level {
+	levels := make([]Level, 0, len(severities))
+	for level := range severities {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i] > levels[j]
+	})
+	return levels
+}
+
+// syslogFormatter formats records as syslog messages.
+type syslogFormatter struct {
+	options   SyslogOptions
+	formatter Formatter
+	// levels holds mapped levels in descending order.
+	levels []Level
+	pid    string
+}
+
+// severity returns syslog severity for provided level.
+func (sf *syslogFormatter) severity(level Level) SyslogSeverity {
+	for _, mapped := range sf.levels {
+		if mapped <= level {
+			return sf.options.Severities[mapped]
+		}
+	}
+	return SeverityInfo
+}
+
+// Format formats record in configured syslog format.
+func (sf *syslogFormatter) Format(record Record) []byte {
+	buff := buffPool.Get()
+	defer buffPool.Put(buff)
+	priority := int(sf.options.Facility)*8 + int(sf.severity(record.Level))
+	buff.WriteRune('<')
+	buff.WriteString(strconv.Itoa(priority))
+	buff.WriteRune('>')
+	if sf.options.Format == SyslogRFC3164 {
+		sf.format3164(buff, record)
+	} else {
+		sf.format5424(buff, record)
+	}
+	buff.Write(bytes.TrimRight(sf.formatter.Format(record), "\n"))
+	return append([]byte(nil), buff.Bytes()...)
+}
+
+// format3164 writes RFC 3164 header to buffer.
+func (sf *syslogFormatter) format3164(buff *bytes.Buffer, record Record) {
+	buff.WriteString(record.Time.Local().Format(time.Stamp))
+	buff.WriteRune(' ')
+	buff.WriteString(syslogHeaderField(sf.options.Hostname, 255))
+	buff.WriteRune(' ')
+	buff.WriteString(syslogHeaderField(sf.options.AppName, 32))
+	buff.WriteRune('[')
+	buff.WriteString(sf.pid)
+	buff.WriteString("]: ")
+}
+
+// format5424 writes RFC 5424 header and structured data to buffer.
+func (sf *syslogFormatter) format5424(buff *bytes.Buffer, record Record) {
+	buff.WriteString("1 ")
+	if record.Time.IsZero() {
+		buff.WriteRune('-')
+	} else {
+		buff.WriteString(record.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	}
+	buff.WriteRune(' ')
+	buff.WriteString(syslogHeaderField(sf.options.Hostname, 255))
+	buff.WriteRune(' ')
+	buff.WriteString(syslogHeaderField(sf.options.AppName, 48))
+	buff.WriteRune(' ')
+	buff.WriteString(sf.pid)
+	buff.WriteString(" - ")
+	if len(record.Context) == 0 && len(record.Fields) == 0 {
+		buff.WriteString("- ")
+		return
+	}
+	keys := make([]string, 0, len(record.Context))
+	for k := range record.Context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buff.WriteRune('[')
+	buff.WriteString(syslogSDName(sf.options.SDID))
+	for _, k := range keys {
+		buff.WriteRune(' ')
+		buff.WriteString(syslogSDName(k))
+		buff.WriteString(`="`)
+		buff.WriteString(syslogSDEscaper.Replace(logfmtValue(record.Context[k])))
+		buff.WriteRune('"')
+	}
+	for _, field := range record.Fields {
+		buff.WriteRune(' ')
+		buff.WriteString(syslogSDName(field.Key))
+		buff.WriteString(`="`)
+		buff.WriteString(syslogSDEscaper.Replace(string(field.AppendText(nil))))
+		buff.WriteRune('"')
+	}
+	buff.WriteString("] ")
+}
+
+// syslogHeaderField returns value suitable for syslog header field, with
+// all characters that are not printable ASCII replaced with underscore and
+// truncated to provided length. Empty value is replaced with "-".
+func syslogHeaderField(value string, maxLen int) string {
+	if value == "" {
+		return "-"
+	}
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
+
+// syslogSDName returns name suitable for structured data ID or parameter
+// name, as defined in RFC 5424.
+func syslogSDName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	return syslogHeaderField(name, 32)
+}
+
+// syslogSDEscaper escapes characters that are not allowed in structured
+// data parameter values.
+var syslogSDEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
@@ -0,0 +1,69 @@
+package ligno
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// receiveSyslog sends record with handler created with provided options to
+// UDP listener and returns received message.
+func receiveSyslog(t *testing.T, record Record, options SyslogOptions) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	handler := RemoteSyslogHandler("udp", conn.LocalAddr().String(), nil, options)
+	defer handler.(HandlerCloser).Close()
+	if err := handler.Handle(record); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buff := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buff[:n])
+}
+
+func TestRemoteSyslogHandlerRFC5424(t *testing.T) {
+	msg := receiveSyslog(t, Record{
+		Time:    time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   ERROR,
+		Message: "failed",
+		Context: Ctx{"user": "bob", "path": `a"b]`},
+	}, SyslogOptions{Facility: FacilityLocal0, Hostname: "host", AppName: "app"})
+	expected := "<131>1 2016-01-02T03:04:05.000000Z host app " + strconv.Itoa(os.Getpid()) +
+		` - [ctx@32473 path="a\"b\]" user="bob"] failed`
+	if msg != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, msg)
+	}
+}
+
+func TestRemoteSyslogHandlerRFC3164CustomLevel(t *testing.T) {
+	notice := Level(25)
+	msg := receiveSyslog(t, Record{
+		Time:    time.Now(),
+		Level:   notice + 1,
+		Message: "noticed",
+	}, SyslogOptions{
+		Format:   SyslogRFC3164,
+		Hostname: "host",
+		AppName:  "app",
+		Severities: map[Level]SyslogSeverity{
+			INFO:   SeverityInfo,
+			notice: SeverityNotice,
+		},
+	})
+	if !strings.HasPrefix(msg, "<13>") {
+		t.Errorf("Expected user facility and notice severity, got %s", msg)
+	}
+	if expected := " host app[" + strconv.Itoa(os.Getpid()) + "]: noticed"; !strings.HasSuffix(msg, expected) {
+		t.Errorf("Expected message to end with %q, got %q", expected, msg)
+	}
+}
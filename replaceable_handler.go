@@ -36,6 +36,13 @@ func (h *replaceableHandler) Close() {
 	}
 }
 
+// Flush is implementation of HandlerFlusher interface.
+// If underlying handler implements HandlerFlusher interface, its Flush
+// method will be called.
+func (h *replaceableHandler) Flush() error {
+	return flushHandler(h.Handler())
+}
+
 // Replace sets provided handler as new underlying handler.
 func (h *replaceableHandler) Replace(newHandler Handler) {
 	atomic.StorePointer(&h.handler, unsafe.Pointer(&newHandler))
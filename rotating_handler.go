@@ -0,0 +1,229 @@
+package ligno
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Commonly used rotation intervals for RotatingFileHandler.
+const (
+	RotateHourly = time.Hour
+	RotateDaily  = 24 * time.Hour
+)
+
+// RotationOptions holds configuration for RotatingFileHandler.
+type RotationOptions struct {
+	// MaxSize is max size of file in bytes. When writing next record would
+	// exceed it, file is rotated. Zero disables size based rotation.
+	MaxSize int64
+	// Interval is period after which file is rotated. Rotation happens on
+	// multiples of interval since zero time, which means that RotateHourly
+	// rotates files on every full hour and RotateDaily at midnight UTC.
+	// Zero disables time based rotation.
+	Interval time.Duration
+	// MaxBackups is number of rotated files to keep. Rotated files are named
+	// as original file with ".1", ".2", etc. suffix, ".1" being the newest.
+	// Zero means that all rotated files are kept.
+	MaxBackups int
+	// Compress is flag that indicates if rotated files should be compressed
+	// with gzip. Compressed files get additional ".gz" suffix.
+	Compress bool
+}
+
+// rotatingFileHandler is file handler that rotates file based on its size
+// and age.
+type rotatingFileHandler struct {
+	fileHandler
+	options RotationOptions
+	mu      sync.Mutex
+	// size is current size of opened file.
+	size int64
+	// nextRotation is time when file should be rotated next time.
+	nextRotation time.Time
+	// now returns current time, replaceable for testing purposes.
+	now func() time.Time
+}
+
+// RotatingFileHandler writes log records to file with provided name and
+// rotates it based on provided options.
+func RotatingFileHandler(fileName string, formatter Formatter, options RotationOptions) Handler {
+	return &rotatingFileHandler{
+		fileHandler: fileHandler{
+			fileName:  fileName,
+			formatter: formatter,
+		},
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Handle writes record to file, rotating it first if needed.
+func (rh *rotatingFileHandler) Handle(record Record) error {
+	msg := rh.formatter.Format(record)
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if err := rh.open(); err != nil {
+		return err
+	}
+	if rh.shouldRotate(len(msg)) {
+		if err := rh.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := rh.f.Write(msg)
+	rh.size += int64(n)
+	return err
+}
+
+// Close closes file were records are being written.
+func (rh *rotatingFileHandler) Close() {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	rh.close()
+}
+
+// open opens file if it is not opened already and initializes information
+// needed for deciding when to rotate it. If file was last written to in
+// previous interval, it is rotated right away.
+func (rh *rotatingFileHandler) open() error {
+	if rh.f != nil {
+		return nil
+	}
+	if err := rh.fileHandler.open(); err != nil {
+		return err
+	}
+	info, err := rh.f.Stat()
+	if err != nil {
+		return err
+	}
+	rh.size = info.Size()
+	rh.scheduleRotation()
+	if rh.options.Interval > 0 && rh.size > 0 && info.ModTime().Before(rh.nextRotation.Add(-rh.options.Interval)) {
+		return rh.rotate()
+	}
+	return nil
+}
+
+// scheduleRotation calculates time of next time based rotation.
+func (rh *rotatingFileHandler) scheduleRotation() {
+	if rh.options.Interval > 0 {
+		rh.nextRotation = rh.now().Truncate(rh.options.Interval).Add(rh.options.Interval)
+	}
+}
+
+// shouldRotate returns true if file should be rotated before writing
+// message of provided size to it.
+func (rh *rotatingFileHandler) shouldRotate(msgSize int) bool {
+	if rh.options.MaxSize > 0 && rh.size > 0 && rh.size+int64(msgSize) > rh.options.MaxSize {
+		return true
+	}
+	return rh.options.Interval > 0 && !rh.now().Before(rh.nextRotation)
+}
+
+// rotate closes current file, shifts existing backups and opens new file.
+// New file is opened even if shifting backups fails, so that records are
+// not lost.
+func (rh *rotatingFileHandler) rotate() error {
+	if err := rh.close(); err != nil {
+		return err
+	}
+	rotateErr := rh.shiftBackups()
+	if err := rh.fileHandler.open(); err != nil {
+		return err
+	}
+	info, err := rh.f.Stat()
+	if err != nil {
+		return err
+	}
+	rh.size = info.Size()
+	rh.scheduleRotation()
+	return rotateErr
+}
+
+// backupName returns name of backup file with provided index.
+func (rh *rotatingFileHandler) backupName(index int) string {
+	return rh.fileName + "." + strconv.Itoa(index)
+}
+
+// existingBackup returns name of backup with provided index (with or
+// without compression suffix) if it exists.
+func (rh *rotatingFileHandler) existingBackup(index int) (string, bool) {
+	for _, name := range []string{rh.backupName(index), rh.backupName(index) + ".gz"} {
+		if _, err := os.Stat(name); err == nil {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// shiftBackups renames current file to first backup, renaming all existing
+// backups to one index higher and removing ones above MaxBackups.
+func (rh *rotatingFileHandler) shiftBackups() error {
+	last := rh.options.MaxBackups
+	if last <= 0 {
+		// keep all backups, find first index that is free
+		last = 1
+		for {
+			if _, ok := rh.existingBackup(last); !ok {
+				break
+			}
+			last++
+		}
+	}
+	if name, ok := rh.existingBackup(last); ok {
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	for i := last - 1; i > 0; i-- {
+		name, ok := rh.existingBackup(i)
+		if !ok {
+			continue
+		}
+		newName := rh.backupName(i + 1)
+		if len(name) > len(rh.backupName(i)) {
+			newName += ".gz"
+		}
+		if err := os.Rename(name, newName); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(rh.fileName, rh.backupName(1)); err != nil {
+		return err
+	}
+	if rh.options.Compress {
+		return compressFile(rh.backupName(1))
+	}
+	return nil
+}
+
+// compressFile compresses file with provided name using gzip and removes
+// original file.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
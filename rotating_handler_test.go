@@ -0,0 +1,72 @@
+package ligno
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileHandlerSize(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	handler := RotatingFileHandler(fileName, SimpleFormat(), RotationOptions{
+		MaxSize:    100,
+		MaxBackups: 2,
+	})
+	defer handler.(HandlerCloser).Close()
+	for i := 0; i < 10; i++ {
+		if err := handler.Handle(Record{Message: strings.Repeat("x", 30)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{fileName, fileName + ".1", fileName + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("File %s is larger then max size: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(fileName + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only two backups to be kept.")
+	}
+}
+
+func TestRotatingFileHandlerIntervalCompress(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2016, 1, 1, 10, 30, 0, 0, time.UTC)
+	handler := RotatingFileHandler(fileName, SimpleFormat(), RotationOptions{
+		Interval: RotateHourly,
+		Compress: true,
+	}).(*rotatingFileHandler)
+	handler.now = func() time.Time { return now }
+	defer handler.Close()
+
+	handler.Handle(Record{Message: "first"})
+	now = now.Add(time.Hour)
+	handler.Handle(Record{Message: "second"})
+
+	f, err := os.Open(fileName + ".1.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "first") || strings.Contains(string(content), "second") {
+		t.Errorf("Unexpected content of rotated file: %q", content)
+	}
+	if _, err := os.Stat(fileName + ".1"); !os.IsNotExist(err) {
+		t.Error("Expected uncompressed backup to be removed.")
+	}
+}
@@ -0,0 +1,188 @@
+package ligno
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SamplingPolicy defines which records are passed by SamplingHandler.
+type SamplingPolicy struct {
+	// Interval is duration of sampling window. Records are counted per
+	// level and message in each window. Zero disables window based
+	// sampling, leaving only probabilistic sampling.
+	Interval time.Duration
+	// First is number of records with same level and message that are
+	// passed in each window.
+	First int
+	// Thereafter defines that after First records, every Thereafter-th
+	// record in window is passed. Zero means that all records after First
+	// are suppressed until window closes.
+	Thereafter int
+	// Probability is probability (between 0 and 1) with which record is
+	// passed. When Interval is set, it is applied only to records passed
+	// by window based sampling. Zero disables probabilistic sampling.
+	Probability float64
+}
+
+// samplingKey identifies records that are sampled together.
+type samplingKey struct {
+	level   Level
+	message string
+}
+
+// samplingWindow holds state of sampling of records with same key.
+type samplingWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	logger     *Logger
+}
+
+// samplingHandler passes only sampled records to wrapped handler.
+type samplingHandler struct {
+	handler Handler
+	policy  SamplingPolicy
+	mu      sync.Mutex
+	windows map[samplingKey]*samplingWindow
+	// nextSweep is time when windows should be checked for expiration.
+	nextSweep time.Time
+	rand      *rand.Rand
+	// now returns current time, replaceable for testing purposes.
+	now func() time.Time
+}
+
+// SamplingHandler creates handler that passes only sampled records to
+// provided handler, in order to cap number of records with same level and
+// message (see SamplingPolicy).
+// When window with suppressed records closes, summary record with number
+// of suppressed records is passed to handler. Expired windows are detected
+// when handler processes next record or when it is closed.
+func SamplingHandler(handler Handler, policy SamplingPolicy) Handler {
+	return &samplingHandler{
+		handler: handler,
+		policy:  policy,
+		windows: make(map[samplingKey]*samplingWindow),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:     time.Now,
+	}
+}
+
+// Handle passes record to wrapped handler if it is sampled, preceded by
+// summary records for all sampling windows that closed.
+func (sh *samplingHandler) Handle(record Record) error {
+	now := sh.now()
+	sh.mu.Lock()
+	summaries := sh.sweep(now)
+	pass, summary := sh.sample(record, now)
+	sh.mu.Unlock()
+	if summary != nil {
+		summaries = append(summaries, *summary)
+	}
+
+	var errs []error
+	for _, s := range summaries {
+		if err := sh.handler.Handle(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if pass {
+		if err := sh.handler.Handle(record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close passes summary records for all windows with suppressed records to
+// wrapped handler and closes it, if it implements HandlerCloser.
+func (sh *samplingHandler) Close() {
+	sh.mu.Lock()
+	var summaries []Record
+	for key, window := range sh.windows {
+		if window.suppressed > 0 {
+			summaries = append(summaries, sh.summary(key, window))
+		}
+		delete(sh.windows, key)
+	}
+	sh.mu.Unlock()
+	for _, s := range summaries {
+		sh.handler.Handle(s)
+	}
+	if handlerCloser, ok := sh.handler.(HandlerCloser); ok {
+		handlerCloser.Close()
+	}
+}
+
+// Flush flushes wrapped handler if it implements HandlerFlusher.
+func (sh *samplingHandler) Flush() error {
+	return flushHandler(sh.handler)
+}
+
+// sample decides if record should be passed. If record starts new window,
+// summary of previous window is returned as well.
+func (sh *samplingHandler) sample(record Record, now time.Time) (bool, *Record) {
+	policy := sh.policy
+	if policy.Interval <= 0 {
+		return policy.Probability <= 0 || sh.rand.Float64() < policy.Probability, nil
+	}
+	var summary *Record
+	key := samplingKey{level: record.Level, message: record.Message}
+	window, ok := sh.windows[key]
+	if !ok || !now.Before(window.start.Add(policy.Interval)) {
+		if ok && window.suppressed > 0 {
+			s := sh.summary(key, window)
+			summary = &s
+		}
+		window = &samplingWindow{start: now}
+		sh.windows[key] = window
+	}
+	window.count++
+	window.logger = record.Logger
+	pass := window.count <= policy.First ||
+		(policy.Thereafter > 0 && (window.count-policy.First)%policy.Thereafter == 0)
+	if pass && policy.Probability > 0 {
+		pass = sh.rand.Float64() < policy.Probability
+	}
+	if !pass {
+		window.suppressed++
+	}
+	return pass, summary
+}
+
+// sweep removes all expired windows and returns summaries for ones that
+// have suppressed records. Windows are checked at most once per interval.
+func (sh *samplingHandler) sweep(now time.Time) []Record {
+	if sh.policy.Interval <= 0 || now.Before(sh.nextSweep) {
+		return nil
+	}
+	sh.nextSweep = now.Add(sh.policy.Interval)
+	var summaries []Record
+	for key, window := range sh.windows {
+		if now.Before(window.start.Add(sh.policy.Interval)) {
+			continue
+		}
+		if window.suppressed > 0 {
+			summaries = append(summaries, sh.summary(key, window))
+		}
+		delete(sh.windows, key)
+	}
+	return summaries
+}
+
+// summary creates record with information about records suppressed in window.
+func (sh *samplingHandler) summary(key samplingKey, window *samplingWindow) Record {
+	return Record{
+		Time:    sh.now().UTC(),
+		Level:   key.level,
+		Message: fmt.Sprintf("%d records suppressed by sampling", window.suppressed),
+		Context: Ctx{
+			"sampled_message": key.message,
+			"suppressed":      window.suppressed,
+			"window":          sh.policy.Interval.String(),
+		},
+		Logger: window.logger,
+	}
+}
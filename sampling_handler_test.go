@@ -0,0 +1,61 @@
+package ligno
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	var handled []Record
+	inner := HandlerFunc(func(record Record) error {
+		handled = append(handled, record)
+		return nil
+	})
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := SamplingHandler(FilterLevelHandler(INFO, inner), SamplingPolicy{
+		Interval:   time.Second,
+		First:      2,
+		Thereafter: 3,
+	}).(*samplingHandler)
+	handler.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		handler.Handle(Record{Level: INFO, Message: "hot loop"})
+	}
+	handler.Handle(Record{Level: INFO, Message: "other"})
+	// records 1, 2, 5 and 8 of hot loop are passed
+	if len(handled) != 5 {
+		t.Fatalf("Expected 5 handled records, got %d", len(handled))
+	}
+
+	now = now.Add(time.Second)
+	handler.Handle(Record{Level: INFO, Message: "hot loop"})
+	if len(handled) != 7 {
+		t.Fatalf("Expected summary and new record to be handled, got %d records", len(handled))
+	}
+	summary := handled[5]
+	if summary.Context["suppressed"] != 6 || summary.Context["sampled_message"] != "hot loop" {
+		t.Errorf("Unexpected summary record: %+v", summary)
+	}
+
+	handler.Handle(Record{Level: INFO, Message: "hot loop"})
+	handler.Handle(Record{Level: INFO, Message: "hot loop"})
+	handler.Close()
+	if last := handled[len(handled)-1]; last.Context["suppressed"] != 1 {
+		t.Errorf("Expected summary to be emitted on close, got %+v", last)
+	}
+}
+
+func TestSamplingHandlerProbability(t *testing.T) {
+	passed := 0
+	handler := SamplingHandler(HandlerFunc(func(Record) error {
+		passed++
+		return nil
+	}), SamplingPolicy{Probability: 0.1})
+	for i := 0; i < 10000; i++ {
+		handler.Handle(Record{Level: INFO, Message: "message"})
+	}
+	if passed < 500 || passed > 1500 {
+		t.Errorf("Expected about 1000 passed records, got %d", passed)
+	}
+}
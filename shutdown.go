@@ -0,0 +1,173 @@
+package ligno
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ShutdownError is returned by Shutdown when some loggers did not process
+// all queued records before context was done.
+type ShutdownError struct {
+	// Loggers holds full names of loggers that failed to drain, root
+	// logger being denoted by empty name.
+	Loggers []string
+	// Err is error of context that ended waiting.
+	Err error
+}
+
+// Error returns description of shutdown failure.
+func (e *ShutdownError) Error() string {
+	names := make([]string, len(e.Loggers))
+	for i, name := range e.Loggers {
+		names[i] = strconv.Quote(name)
+	}
+	return fmt.Sprintf("ligno: loggers failed to drain: %s: %v", strings.Join(names, ", "), e.Err)
+}
+
+// Unwrap returns error of context that ended waiting.
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// Shutdown stops all loggers in tree and closes their handlers. New records
+// are rejected by all loggers right away, while records that are already
+// queued are processed. Children are drained before their parents, since
+// they propagate records to them. Handlers are closed only once, even if
+// they are shared by multiple loggers.
+// If context is done before some loggers process all queued records and
+// flush their handlers, ShutdownError listing them is returned. Such loggers
+// keep processing queued records in background and their handlers are
+// closed once all loggers using them are finished.
+// Loggers are not usable after shutdown, records sent to them are silently
+// dropped.
+func Shutdown(ctx context.Context) error {
+	return rootLogger.shutdownTree(ctx)
+}
+
+// shutdownTree stops this logger and all its descendants and closes their
+// handlers, as described for Shutdown.
+func (l *Logger) shutdownTree(ctx context.Context) error {
+	// stop accepting new records everywhere before draining anything
+	l.walk(func(logger *Logger) {
+		logger.state.Lock()
+		if logger.state.val == loggerRunning {
+			logger.state.val = loggerDraining
+		}
+		logger.state.Unlock()
+	})
+
+	var mu sync.Mutex
+	// handlers holds distinct handlers of stopped loggers and pending
+	// holds, for each of them, channels of failed loggers that still use it
+	var handlers []Handler
+	var pending [][]<-chan struct{}
+	var failed []string
+	l.shutdown(ctx, func(logger *Logger, finished <-chan struct{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		handler := logger.Handler()
+		i := handlerIndex(handlers, handler)
+		if i < 0 {
+			handlers = append(handlers, handler)
+			pending = append(pending, nil)
+			i = len(handlers) - 1
+		}
+		if finished != nil {
+			pending[i] = append(pending[i], finished)
+			failed = append(failed, logger.FullName())
+		}
+	})
+
+	for i, handler := range handlers {
+		handlerCloser, ok := handler.(HandlerCloser)
+		if !ok {
+			continue
+		}
+		if len(pending[i]) == 0 {
+			handlerCloser.Close()
+			continue
+		}
+		go func(waits []<-chan struct{}) {
+			for _, finished := range waits {
+				<-finished
+			}
+			handlerCloser.Close()
+		}(pending[i])
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return &ShutdownError{Loggers: failed, Err: ctx.Err()}
+}
+
+// shutdown stops this logger and all its descendants, children being
+// stopped before parents, and reports each of them. Loggers that processed
+// all queued records before context was done are reported with nil
+// channel, others with channel that is closed once they are finished.
+// Worker goroutines of logger exit once all queued records are processed,
+// even if context is done before that.
+func (l *Logger) shutdown(ctx context.Context, report func(l *Logger, finished <-chan struct{})) {
+	children := l.children()
+	var wg sync.WaitGroup
+	wg.Add(len(children))
+	for _, child := range children {
+		go func(child *Logger) {
+			child.shutdown(ctx, report)
+			wg.Done()
+		}(child)
+	}
+	wg.Wait()
+
+	// children are stopped, so no more records can be propagated to this
+	// logger and it can be stopped as well
+	l.state.Lock()
+	if l.state.val == loggerStopped {
+		l.state.Unlock()
+		return
+	}
+	l.state.val = loggerStopped
+	close(l.rawRecords)
+	l.state.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		l.drain()
+		// stop worker goroutines
+		close(l.records)
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		// context might be done while waiting for children, logger is still
+		// drained if it has nothing left to process
+		if atomic.LoadInt32(&l.toProcess) != 0 {
+			report(l, drained)
+			return
+		}
+		<-drained
+	}
+	// flush handler only if there is time left, since handlers that buffer
+	// records flush them when closed anyway
+	if ctx.Err() == nil {
+		flushed := make(chan struct{})
+		go func() {
+			l.flush()
+			close(flushed)
+		}()
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			report(l, flushed)
+			return
+		}
+	}
+	report(l, nil)
+}
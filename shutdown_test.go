@@ -0,0 +1,205 @@
+package ligno
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownClosesSharedHandlerOnce(t *testing.T) {
+	shared := new(closeTrackingHandler)
+	root := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            shared,
+		PreventPropagation: true,
+	})
+	a := root.SubLoggerOptions("a", LoggerOptions{Handler: shared})
+	b := a.SubLoggerOptions("b", LoggerOptions{Handler: shared})
+	own := new(closeTrackingHandler)
+	root.SubLoggerOptions("c", LoggerOptions{Handler: own})
+
+	if err := root.shutdownTree(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if closed := atomic.LoadInt32(&shared.closed); closed != 1 {
+		t.Errorf("Expected shared handler to be closed once, got %d", closed)
+	}
+	if closed := atomic.LoadInt32(&own.closed); closed != 1 {
+		t.Errorf("Expected own handler to be closed once, got %d", closed)
+	}
+	// stopped loggers drop records and can be stopped again
+	b.Info("dropped")
+	b.StopAndWait()
+	a.StopAndWait()
+	if closed := atomic.LoadInt32(&shared.closed); closed != 1 {
+		t.Errorf("Expected shared handler not to be closed again, got %d", closed)
+	}
+}
+
+// flushOrderHandler is handler that records when it is flushed.
+type flushOrderHandler struct {
+	name    string
+	mu      *sync.Mutex
+	order   *[]string
+	handled int32
+}
+
+func (h *flushOrderHandler) Handle(Record) error {
+	atomic.AddInt32(&h.handled, 1)
+	return nil
+}
+
+func (h *flushOrderHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.order = append(*h.order, h.name)
+	return nil
+}
+
+func TestShutdownDrainsChildrenBeforeParents(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	parentHandler := &flushOrderHandler{name: "parent", mu: &mu, order: &order}
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            parentHandler,
+		PreventPropagation: true,
+	})
+	childHandler := &flushOrderHandler{name: "child", mu: &mu, order: &order}
+	child := parent.SubLoggerOptions("child", LoggerOptions{
+		Handler: childHandler,
+		// slow down processing, so that records are propagated to parent
+		// only while shutdown is already in progress
+		Processors: []Processor{func(record Record) Record {
+			time.Sleep(10 * time.Millisecond)
+			return record
+		}},
+	})
+	for i := 0; i < 5; i++ {
+		child.Info("message")
+	}
+
+	if err := parent.shutdownTree(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if handled := atomic.LoadInt32(&childHandler.handled); handled != 5 {
+		t.Errorf("Expected all records to be handled by child, got %d", handled)
+	}
+	if handled := atomic.LoadInt32(&parentHandler.handled); handled != 5 {
+		t.Errorf("Expected all records propagated from child to be handled by parent, got %d", handled)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "child" || order[1] != "parent" {
+		t.Errorf("Expected child to be drained before parent, got %v", order)
+	}
+}
+
+func TestShutdownReportsLoggersNotDrained(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	parentHandler := new(closeTrackingHandler)
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            parentHandler,
+		PreventPropagation: true,
+	})
+	slow := parent.SubLoggerOptions("slow", LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			<-release
+			return nil
+		}),
+		PreventPropagation: true,
+	})
+	slow.Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := parent.shutdownTree(ctx)
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("Expected ShutdownError, got %v", err)
+	}
+	if len(shutdownErr.Loggers) != 1 || shutdownErr.Loggers[0] != slow.FullName() {
+		t.Errorf("Expected only %q to be reported, got %v", slow.FullName(), shutdownErr.Loggers)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to wrap context error, got %v", err)
+	}
+	if closed := atomic.LoadInt32(&parentHandler.closed); closed != 1 {
+		t.Errorf("Expected handler of drained logger to be closed, got %d", closed)
+	}
+}
+
+// blockingFlushHandler is handler whose Flush blocks until released.
+type blockingFlushHandler struct {
+	release chan struct{}
+}
+
+func (h *blockingFlushHandler) Handle(Record) error {
+	return nil
+}
+
+func (h *blockingFlushHandler) Flush() error {
+	<-h.release
+	return nil
+}
+
+func TestShutdownBoundsFlush(t *testing.T) {
+	handler := &blockingFlushHandler{release: make(chan struct{})}
+	defer close(handler.release)
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            handler,
+		PreventPropagation: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- l.shutdownTree(ctx)
+	}()
+	select {
+	case err := <-done:
+		var shutdownErr *ShutdownError
+		if !errors.As(err, &shutdownErr) || len(shutdownErr.Loggers) != 1 {
+			t.Errorf("Expected logger with blocked flush to be reported, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected shutdown to return once context is done.")
+	}
+}
+
+func TestShutdownTimeoutReleasesGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	release := make(chan struct{})
+	handler := new(closeTrackingHandler)
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler: CombiningHandler(HandlerFunc(func(record Record) error {
+			<-release
+			return nil
+		}), handler),
+		PreventPropagation: true,
+	})
+	l.Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.shutdownTree(ctx); err == nil {
+		t.Fatal("Expected logger with stuck handler to be reported.")
+	}
+	if atomic.LoadInt32(&handler.closed) != 0 {
+		t.Error("Expected handler of logger that is still processing records not to be closed.")
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before || atomic.LoadInt32(&handler.closed) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected goroutines to exit and handler to be closed, got %d goroutines (%d before) and %d closes",
+				runtime.NumGoroutine(), before, atomic.LoadInt32(&handler.closed))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
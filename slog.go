@@ -0,0 +1,213 @@
+package ligno
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// LevelFromSlog converts slog level to ligno level. Standard slog levels are
+// mapped to ligno levels with same name (slog.LevelWarn to WARNING) and
+// levels above slog.LevelError are mapped to CRITICAL. Levels in between
+// standard ones are scaled linearly, so custom levels keep their relative
+// ordering.
+func LevelFromSlog(level slog.Level) Level {
+	if level > slog.LevelError {
+		return CRITICAL
+	}
+	l := int(INFO) + int(level)*10/4
+	if l < int(NOTSET) {
+		return NOTSET
+	}
+	return Level(l)
+}
+
+// LevelToSlog converts ligno level to slog level. It is inverse of
+// LevelFromSlog for all builtin levels.
+func LevelToSlog(level Level) slog.Level {
+	return slog.Level((int(level) - int(INFO)) * 4 / 10)
+}
+
+// slogHandler is implementation of slog.Handler that sends records to
+// ligno logger.
+type slogHandler struct {
+	logger *Logger
+	// attrs holds context from attributes added with WithAttrs.
+	attrs Ctx
+	// prefix is prefix for keys of attributes, built from group names.
+	prefix string
+}
+
+// NewSlogHandler creates slog.Handler that sends all records to provided
+// ligno logger, so they are processed by its async pipeline, context and
+// handlers. Attributes in groups are flattened to context keys with group
+// names separated by ".".
+func NewSlogHandler(logger *Logger) slog.Handler {
+	return &slogHandler{logger: logger}
+}
+
+// Enabled reports whether logger processes records in provided level.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsEnabledFor(LevelFromSlog(level))
+}
+
+// Handle converts slog record to ligno record and queues it for processing.
+// Values extracted from context.Context by registered extractors are added
+// to record as well.
+func (h *slogHandler) Handle(goCtx context.Context, r slog.Record) error {
+	ctx := make(Ctx, len(h.logger.bound)+len(h.attrs)+r.NumAttrs())
+	for k, v := range h.logger.bound {
+		ctx[k] = v
+	}
+	extractContext(goCtx, ctx)
+	for k, v := range h.attrs {
+		ctx[k] = v
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(ctx, h.prefix, attr)
+		return true
+	})
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	record := Record{
+		Time:    t.UTC(),
+		Level:   LevelFromSlog(r.Level),
+		Message: r.Message,
+		Context: ctx,
+		Logger:  h.logger,
+	}
+	if h.logger.IncludeFileAndLine() && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		record.File = frame.File
+		record.Line = frame.Line
+		record.pc = r.PC
+	}
+	// call depth of slog caller is not known, so stack trace is captured
+	// here, starting with frame of slog caller
+	if stackTraceLevel := h.logger.StackTraceLevel(); stackTraceLevel != NOTSET && record.Level >= stackTraceLevel {
+		record.Stack = captureStackFrom(r.PC)
+	}
+	h.logger.log(0, record)
+	return nil
+}
+
+// WithAttrs returns new handler that adds provided attributes to all records.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	newAttrs := make(Ctx, len(h.attrs)+len(attrs))
+	for k, v := range h.attrs {
+		newAttrs[k] = v
+	}
+	for _, attr := range attrs {
+		addSlogAttr(newAttrs, h.prefix, attr)
+	}
+	return &slogHandler{logger: h.logger, attrs: newAttrs, prefix: h.prefix}
+}
+
+// WithGroup returns new handler that puts all following attributes in
+// group with provided name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr resolves provided attribute and adds it to context. Groups
+// are flattened with group name used as prefix for keys of its attributes.
+func addSlogAttr(ctx Ctx, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addSlogAttr(ctx, groupPrefix, groupAttr)
+		}
+		return
+	}
+	ctx[prefix+attr.Key] = attr.Value.Any()
+}
+
+// forwardingSlogHandler is ligno handler that forwards records to slog handler.
+type forwardingSlogHandler struct {
+	handler slog.Handler
+}
+
+// SlogHandler creates ligno handler that forwards all records to provided
+// slog handler. Context keys are converted to attributes in sorted order and
+// nested contexts are converted to groups.
+func SlogHandler(handler slog.Handler) Handler {
+	return &forwardingSlogHandler{handler: handler}
+}
+
+// Handle converts ligno record to slog record and passes it to slog handler.
+func (fh *forwardingSlogHandler) Handle(record Record) error {
+	level := LevelToSlog(record.Level)
+	ctx := context.Background()
+	if !fh.handler.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(record.Time, level, record.Message, record.pc)
+	r.AddAttrs(ctxToSlogAttrs(record.Context)...)
+	for _, field := range record.Fields {
+		r.AddAttrs(fieldToSlogAttr(field))
+	}
+	return fh.handler.Handle(ctx, r)
+}
+
+// Close closes underlying handler if it implements HandlerCloser.
+func (fh *forwardingSlogHandler) Close() {
+	if closer, ok := fh.handler.(HandlerCloser); ok {
+		closer.Close()
+	}
+}
+
+// ctxToSlogAttrs converts context to slice of slog attributes sorted by key.
+func ctxToSlogAttrs(ctx Ctx) []slog.Attr {
+	keys := make([]string, 0, len(ctx))
+	for k := range ctx {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		switch v := ctx[k].(type) {
+		case Ctx:
+			attrs = append(attrs, slog.Attr{Key: k, Value: slog.GroupValue(ctxToSlogAttrs(v)...)})
+		case map[string]interface{}:
+			attrs = append(attrs, slog.Attr{Key: k, Value: slog.GroupValue(ctxToSlogAttrs(v)...)})
+		default:
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+	return attrs
+}
+
+// fieldToSlogAttr converts field to slog attribute, keeping its type.
+func fieldToSlogAttr(field Field) slog.Attr {
+	switch field.Type {
+	case StringType:
+		return slog.String(field.Key, field.String)
+	case IntType:
+		return slog.Int64(field.Key, field.Integer)
+	case UintType:
+		return slog.Uint64(field.Key, uint64(field.Integer))
+	case FloatType, BoolType, TimeType:
+		return slog.Any(field.Key, field.Value())
+	case DurationType:
+		return slog.Duration(field.Key, time.Duration(field.Integer))
+	default:
+		return slog.Any(field.Key, field.Interface)
+	}
+}
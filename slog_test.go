@@ -0,0 +1,151 @@
+package ligno
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type secret string
+
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+func TestLevelSlogMapping(t *testing.T) {
+	for level, slogLevel := range map[Level]slog.Level{
+		DEBUG:    slog.LevelDebug,
+		INFO:     slog.LevelInfo,
+		WARNING:  slog.LevelWarn,
+		ERROR:    slog.LevelError,
+		CRITICAL: slog.LevelError + 4,
+	} {
+		if got := LevelToSlog(level); got != slogLevel {
+			t.Errorf("Expected %s to be mapped to %s, got %s", level, slogLevel, got)
+		}
+		if got := LevelFromSlog(slogLevel); got != level {
+			t.Errorf("Expected %s to be mapped to %s, got %s", slogLevel, level, got)
+		}
+	}
+	for _, slogLevel := range []slog.Level{slog.LevelError + 1, slog.LevelError + 100} {
+		if got := LevelFromSlog(slogLevel); got != CRITICAL {
+			t.Errorf("Expected %s to be mapped to CRITICAL, got %s", slogLevel, got)
+		}
+	}
+}
+
+func TestSlogToLigno(t *testing.T) {
+	var mu sync.Mutex
+	var records []Record
+	l := GetLoggerOptions("slog."+randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+			return nil
+		}),
+		Level:              INFO,
+		PreventPropagation: true,
+	})
+	logger := slog.New(NewSlogHandler(l)).With("service", "api").WithGroup("req")
+	logger.Debug("discarded")
+	logger.Warn("slow request", "id", 42, "token", secret("abc"), slog.Group("client", "ip", "127.0.0.1"))
+	l.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(records))
+	}
+	r := records[0]
+	if r.Level != WARNING || r.Message != "slow request" {
+		t.Errorf("Unexpected record: %+v", r)
+	}
+	expected := Ctx{"service": "api", "req.id": int64(42), "req.token": "***", "req.client.ip": "127.0.0.1"}
+	for k, v := range expected {
+		if r.Context[k] != v {
+			t.Errorf("Expected context %s=%v, got %v", k, v, r.Context[k])
+		}
+	}
+}
+
+func TestLignoToSlog(t *testing.T) {
+	buff := new(bytes.Buffer)
+	handler := SlogHandler(slog.NewJSONHandler(buff, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	handler.Handle(Record{Time: time.Now(), Level: DEBUG, Message: "discarded"})
+	handler.Handle(Record{
+		Time:    time.Now(),
+		Level:   ERROR,
+		Message: "failed",
+		Context: Ctx{"user": "john", "request": Ctx{"id": 1}},
+	})
+	var out map[string]interface{}
+	if err := json.Unmarshal(buff.Bytes(), &out); err != nil {
+		t.Fatalf("Expected single JSON record, got %q: %v", buff.String(), err)
+	}
+	if out["level"] != "ERROR" || out["msg"] != "failed" || out["user"] != "john" {
+		t.Errorf("Unexpected output: %v", out)
+	}
+	if request, ok := out["request"].(map[string]interface{}); !ok || request["id"] != float64(1) {
+		t.Errorf("Expected nested context as group, got %v", out["request"])
+	}
+}
+
+func TestSlogToLignoStackTrace(t *testing.T) {
+	var mu sync.Mutex
+	var records []Record
+	l := GetLoggerOptions("slog."+randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+			return nil
+		}),
+		StackTraceLevel:    ERROR,
+		PreventPropagation: true,
+	})
+	logger := slog.New(NewSlogHandler(l))
+	logger.Warn("no stack")
+	logger.Error("with stack")
+	l.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 2 {
+		t.Fatalf("Expected two records, got %d", len(records))
+	}
+	if len(records[0].Stack) != 0 {
+		t.Errorf("Expected no stack trace below stack trace level, got %v", records[0].Stack)
+	}
+	stack := records[1].Stack
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestSlogToLignoStackTrace") {
+		t.Errorf("Expected stack trace to start with slog caller, got %v", stack)
+	}
+}
+
+func TestLignoToSlogSource(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := GetLoggerOptions("slog."+randString(), LoggerOptions{
+		Handler:            SlogHandler(slog.NewJSONHandler(buff, &slog.HandlerOptions{AddSource: true})),
+		IncludeFileAndLine: true,
+		PreventPropagation: true,
+	})
+	l.Info("with source")
+	l.Wait()
+
+	var out struct {
+		Source struct {
+			File string `json:"file"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &out); err != nil {
+		t.Fatalf("Expected single JSON record, got %q: %v", buff.String(), err)
+	}
+	if !strings.HasSuffix(out.Source.File, "slog_test.go") {
+		t.Errorf("Expected source to point to caller, got %q", out.Source.File)
+	}
+}
@@ -0,0 +1,142 @@
+package ligno
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds runtime statistics of logger.
+type Stats struct {
+	// Queued is number of records waiting to be processed.
+	Queued int
+	// Accepted is number of records accepted for processing, including
+	// ones that were later dropped.
+	Accepted uint64
+	// Dropped is number of records discarded because buffer was full.
+	Dropped uint64
+	// HandlerErrors is number of records that handler failed to process.
+	HandlerErrors uint64
+	// MaxLatency is longest time record spent between being queued and
+	// being passed to handler.
+	MaxLatency time.Duration
+	// AvgLatency is average time records spent between being queued and
+	// being passed to handler.
+	AvgLatency time.Duration
+	// Levels holds number of processed records per level.
+	Levels map[Level]uint64
+}
+
+// loggerStats collects statistics about records processed by logger.
+type loggerStats struct {
+	sync.Mutex
+	handled      uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	levels       map[Level]uint64
+}
+
+// observe updates statistics with record that is about to be handled.
+func (ls *loggerStats) observe(record Record) {
+	latency := time.Since(record.enqueued)
+	ls.Lock()
+	defer ls.Unlock()
+	ls.handled++
+	ls.totalLatency += latency
+	if latency > ls.maxLatency {
+		ls.maxLatency = latency
+	}
+	if ls.levels == nil {
+		ls.levels = make(map[Level]uint64)
+	}
+	ls.levels[record.Level]++
+}
+
+// Stats returns current statistics of this logger. Records propagated from
+// children are included, since this logger processes them as well.
+func (l *Logger) Stats() Stats {
+	stats := Stats{
+		Queued:        int(atomic.LoadInt32(&l.toProcess)),
+		Accepted:      atomic.LoadUint64(&l.accepted),
+		Dropped:       l.Dropped(),
+		HandlerErrors: atomic.LoadUint64(&l.handlerErrors),
+	}
+	l.stats.Lock()
+	defer l.stats.Unlock()
+	stats.MaxLatency = l.stats.maxLatency
+	if l.stats.handled > 0 {
+		stats.AvgLatency = l.stats.totalLatency / time.Duration(l.stats.handled)
+	}
+	stats.Levels = make(map[Level]uint64, len(l.stats.levels))
+	for level, count := range l.stats.levels {
+		stats.Levels[level] = count
+	}
+	return stats
+}
+
+// AllStats returns statistics aggregated over all loggers in tree. Since
+// records are propagated to parents, record is counted once for every
+// logger that processed it.
+func AllStats() Stats {
+	total := Stats{Levels: make(map[Level]uint64)}
+	var handled uint64
+	var totalLatency time.Duration
+	rootLogger.walk(func(l *Logger) {
+		stats := l.Stats()
+		total.Queued += stats.Queued
+		total.Accepted += stats.Accepted
+		total.Dropped += stats.Dropped
+		total.HandlerErrors += stats.HandlerErrors
+		if stats.MaxLatency > total.MaxLatency {
+			total.MaxLatency = stats.MaxLatency
+		}
+		var loggerHandled uint64
+		for level, count := range stats.Levels {
+			total.Levels[level] += count
+			loggerHandled += count
+		}
+		handled += loggerHandled
+		totalLatency += stats.AvgLatency * time.Duration(loggerHandled)
+	})
+	if handled > 0 {
+		total.AvgLatency = totalLatency / time.Duration(handled)
+	}
+	return total
+}
+
+// expvarStats converts statistics to format suitable for publishing with
+// expvar, with levels identified by their names and latencies in
+// nanoseconds.
+func expvarStats(stats Stats) map[string]interface{} {
+	levels := make(map[string]uint64, len(stats.Levels))
+	for level, count := range stats.Levels {
+		levels[level.String()] = count
+	}
+	return map[string]interface{}{
+		"queued":         stats.Queued,
+		"accepted":       stats.Accepted,
+		"dropped":        stats.Dropped,
+		"handler_errors": stats.HandlerErrors,
+		"max_latency_ns": int64(stats.MaxLatency),
+		"avg_latency_ns": int64(stats.AvgLatency),
+		"levels":         levels,
+	}
+}
+
+// PublishExpvar publishes statistics of all loggers as expvar variable
+// with provided name. Variable holds aggregated statistics under "total"
+// key and statistics of each logger, by its full name, under "loggers" key.
+// Like expvar.Publish, it panics if variable with same name already exists.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		loggers := make(map[string]interface{})
+		rootLogger.walk(func(l *Logger) {
+			loggers[l.FullName()] = expvarStats(l.Stats())
+		})
+		return map[string]interface{}{
+			"total":   expvarStats(AllStats()),
+			"loggers": loggers,
+		}
+	}))
+}
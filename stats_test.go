@@ -0,0 +1,62 @@
+package ligno
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+)
+
+func TestLoggerStats(t *testing.T) {
+	l := GetLoggerOptions(randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			if record.Level == ERROR {
+				return errors.New("failed")
+			}
+			return nil
+		}),
+		ErrorHandler:       func(Record, Handler, error) {},
+		PreventPropagation: true,
+	})
+	defer l.StopAndWait()
+	l.Debug("debug")
+	l.Info("info")
+	l.Info("info")
+	l.Error("error")
+	l.Wait()
+
+	stats := l.Stats()
+	if stats.Accepted != 4 || stats.Queued != 0 || stats.Dropped != 0 {
+		t.Errorf("Unexpected counters: %+v", stats)
+	}
+	if stats.HandlerErrors != 1 {
+		t.Errorf("Expected 1 handler error, got %d", stats.HandlerErrors)
+	}
+	if stats.Levels[INFO] != 2 || stats.Levels[DEBUG] != 1 || stats.Levels[ERROR] != 1 {
+		t.Errorf("Unexpected level counters: %v", stats.Levels)
+	}
+	if stats.MaxLatency < stats.AvgLatency || stats.AvgLatency <= 0 {
+		t.Errorf("Unexpected latencies, max: %v, avg: %v", stats.MaxLatency, stats.AvgLatency)
+	}
+	if all := AllStats(); all.Accepted < stats.Accepted || all.HandlerErrors < 1 {
+		t.Errorf("Expected logger stats to be included in aggregated stats: %+v", all)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	name := "ligno_test_" + randString()
+	PublishExpvar(name)
+	var published struct {
+		Total   map[string]interface{}            `json:"total"`
+		Loggers map[string]map[string]interface{} `json:"loggers"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := published.Total["accepted"]; !ok {
+		t.Errorf("Expected total stats, got %v", published.Total)
+	}
+	if _, ok := published.Loggers[""]; !ok {
+		t.Error("Expected root logger stats to be published.")
+	}
+}
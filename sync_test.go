@@ -0,0 +1,113 @@
+package ligno
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSynchronousLogger(t *testing.T) {
+	parentHandler := MemoryHandler(SimpleFormat())
+	parent := GetLoggerOptions(randString(), LoggerOptions{
+		Handler:            parentHandler,
+		PreventPropagation: true,
+	})
+	defer parent.StopAndWait()
+	childHandler := MemoryHandler(SimpleFormat())
+	child := parent.SubLoggerOptions("child", LoggerOptions{
+		Handler:     childHandler,
+		Synchronous: true,
+	})
+	defer child.StopAndWait()
+
+	child.InfoF("first")
+	child.Info("second")
+	if messages := childHandler.Messages(); len(messages) != 2 {
+		t.Fatalf("Expected records to be handled before logging returns, got %v", messages)
+	}
+	parent.Wait()
+	if messages := parentHandler.Messages(); len(messages) != 2 {
+		t.Errorf("Expected records to be propagated to async parent, got %v", messages)
+	}
+
+	parent.SetSynchronous(true)
+	child.Info("third")
+	if messages := parentHandler.Messages(); len(messages) != 3 {
+		t.Errorf("Expected record to be handled by synchronous parent right away, got %v", messages)
+	}
+}
+
+func TestSetSynchronous(t *testing.T) {
+	existing := GetLogger(randString())
+	explicitSync := GetLoggerOptions(randString(), LoggerOptions{Synchronous: true})
+	explicitAsync := GetLogger(randString())
+	explicitAsync.SetSynchronous(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	created := GetLoggerOptions(randString(), LoggerOptions{})
+	if !existing.IsSynchronous() || !created.IsSynchronous() || !rootLogger.IsSynchronous() {
+		t.Error("Expected existing and new loggers to be synchronous.")
+	}
+	if explicitAsync.IsSynchronous() {
+		t.Error("Expected explicitly async logger to stay async.")
+	}
+	SetSynchronous(false)
+	if existing.IsSynchronous() || created.IsSynchronous() {
+		t.Error("Expected loggers without explicit mode to follow default.")
+	}
+	if !explicitSync.IsSynchronous() {
+		t.Error("Expected explicitly synchronous logger to stay synchronous.")
+	}
+}
+
+func TestConfigureKeepsSynchronous(t *testing.T) {
+	name := "sync." + randString()
+	l := GetLoggerOptions(name, LoggerOptions{Synchronous: true, PreventPropagation: true})
+	doc := `loggers: {` + name + `: {level: INFO, prevent_propagation: true}}`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if !l.IsSynchronous() {
+		t.Error("Expected configuration without synchronous flag to keep mode of logger.")
+	}
+	doc = `loggers: {` + name + `: {synchronous: false, prevent_propagation: true}}`
+	if err := ConfigureFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if l.IsSynchronous() {
+		t.Error("Expected configuration to change mode of logger.")
+	}
+}
+
+func TestSynchronousErrorHandlerLogs(t *testing.T) {
+	var l *Logger
+	handler := MemoryHandler(SimpleFormat())
+	l = GetLoggerOptions(randString(), LoggerOptions{
+		Handler: HandlerFunc(func(record Record) error {
+			if record.Message == "fail" {
+				return errors.New("failed")
+			}
+			return handler.Handle(record)
+		}),
+		ErrorHandler: func(record Record, _ Handler, err error) {
+			l.Error("handler error", "error", err)
+		},
+		PreventPropagation: true,
+		Synchronous:        true,
+	})
+	defer l.StopAndWait()
+	done := make(chan struct{})
+	go func() {
+		l.Info("fail")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected error handler to be able to log with synchronous logger.")
+	}
+	if messages := handler.Messages(); len(messages) != 1 || !strings.Contains(messages[0], "handler error") {
+		t.Errorf("Expected record logged by error handler, got %v", messages)
+	}
+}
@@ -2,7 +2,9 @@
 
 package ligno
 
-import "log/syslog"
+import (
+	"log/syslog"
+)
 
 // syslogHandler sends all messages to local syslog server.
 type syslogHandler struct {
@@ -13,17 +15,29 @@ type syslogHandler struct {
 }
 
 // SyslogHandler creates new syslog handler with provided config variables.
+// It panics if connection to syslog server can not be established, use
+// NewSyslogHandler to get error instead.
 func SyslogHandler(formatter Formatter, tag string, priority syslog.Priority) Handler {
-	writer, err := syslog.New(syslog.LOG_DEBUG, tag)
+	handler, err := NewSyslogHandler(formatter, tag, priority)
 	if err != nil {
 		panic(err)
 	}
+	return handler
+}
+
+// NewSyslogHandler creates new syslog handler with provided config variables
+// and returns error if connection to syslog server can not be established.
+func NewSyslogHandler(formatter Formatter, tag string, priority syslog.Priority) (Handler, error) {
+	writer, err := syslog.New(priority, tag)
+	if err != nil {
+		return nil, err
+	}
 	return &syslogHandler{
 		Formatter: formatter,
 		Tag:       tag,
 		Priority:  priority,
 		writer:    writer,
-	}
+	}, nil
 }
 
 // Handle passes all messages to syslog server. Message priorities are
@@ -53,3 +67,20 @@ func (sh *syslogHandler) Handle(record Record) error {
 func (sh *syslogHandler) Close() {
 	sh.writer.Close()
 }
+
+func init() {
+	RegisterHandlerType("syslog", func(config HandlerConfig, refs ConfigRefs) (Handler, error) {
+		var options struct {
+			Tag      string `json:"tag"`
+			Priority int    `json:"priority"`
+		}
+		if err := config.DecodeOptions(&options); err != nil {
+			return nil, err
+		}
+		formatter, err := refs.Formatter(config.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		return NewSyslogHandler(formatter, options.Tag, syslog.Priority(options.Priority))
+	})
+}
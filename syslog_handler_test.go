@@ -0,0 +1,22 @@
+// +build !windows,!nacl,!plan9
+
+package ligno
+
+import (
+	"log/syslog"
+	"strings"
+	"testing"
+)
+
+func TestConfigureSyslogUnavailable(t *testing.T) {
+	if writer, err := syslog.New(syslog.LOG_INFO, "ligno"); err == nil {
+		writer.Close()
+		t.Skip("local syslog server is available")
+	}
+	if handler, err := NewSyslogHandler(SimpleFormat(), "ligno", syslog.LOG_INFO); err == nil || handler != nil {
+		t.Errorf("Expected error without handler, got %v, %v", handler, err)
+	}
+	if err := ConfigureFromReader(strings.NewReader(`handlers: {h: {type: syslog, options: {tag: ligno}}}`)); err == nil {
+		t.Error("Expected error when syslog server is unavailable.")
+	}
+}